@@ -5,6 +5,8 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"net/netip"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
@@ -42,47 +44,253 @@ type client struct {
 // make it possible to mock connection ID for initial generation in the tests
 var generateConnectionIDForInitial = protocol.GenerateConnectionIDForInitial
 
+// connectionAttemptDelay is the delay between two connection attempts
+// when dialing a host that resolves to multiple addresses, see section 5 of RFC 8305.
+var connectionAttemptDelay = 250 * time.Millisecond
+
 // DialAddr establishes a new QUIC connection to a server.
 // It resolves the address, and then creates a new UDP connection to dial the QUIC server.
 // When the QUIC connection is closed, this UDP connection is closed.
+// If the host resolves to multiple addresses, connection attempts are raced
+// following the Happy Eyeballs algorithm (RFC 8305): IPv6 and IPv4 addresses are tried alternately,
+// starting a new attempt every 250ms (or as soon as the previous attempt failed),
+// and the first connection to complete the handshake is returned.
+// A custom resolver can be configured using Config.Resolver.
+// The ServerName of the tls.Config, if set, is used to validate the server's certificate,
+// otherwise the host is used.
+// This allows dialing an IP address while validating a different host name.
 // See Dial for more details.
 func DialAddr(ctx context.Context, addr string, tlsConf *tls.Config, conf *Config) (Connection, error) {
-	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
-	if err != nil {
-		return nil, err
-	}
-	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	conn, err := dialAddr(ctx, addr, tlsConf, conf, false)
 	if err != nil {
 		return nil, err
 	}
-	tr, err := setupTransport(udpConn, tlsConf, true)
-	if err != nil {
-		return nil, err
-	}
-	return tr.dial(ctx, udpAddr, addr, tlsConf, conf, false)
+	return conn, nil
 }
 
 // DialAddrEarly establishes a new 0-RTT QUIC connection to a server.
 // See DialAddr for more details.
 func DialAddrEarly(ctx context.Context, addr string, tlsConf *tls.Config, conf *Config) (EarlyConnection, error) {
-	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
+	return dialAddr(ctx, addr, tlsConf, conf, true)
+}
+
+func dialAddr(ctx context.Context, addr string, tlsConf *tls.Config, conf *Config, use0RTT bool) (EarlyConnection, error) {
+	if tlsConf == nil {
+		return nil, errors.New("quic: tls.Config not set")
+	}
+	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, err
 	}
-	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	resolver := net.DefaultResolver
+	if conf != nil && conf.Resolver != nil {
+		resolver = conf.Resolver
+	}
+	portNum, err := resolver.LookupPort(ctx, "udp", port)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
 		return nil, err
 	}
-	tr, err := setupTransport(udpConn, tlsConf, true)
+	ips, err := lookupIPAddr(ctx, resolver, host)
 	if err != nil {
+		// The resolver returns a *net.DNSError when the context is canceled.
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
 		return nil, err
 	}
-	conn, err := tr.dial(ctx, udpAddr, addr, tlsConf, conf, true)
+	raddrs := make([]*net.UDPAddr, 0, len(ips))
+	for _, ip := range interleaveAddrFamilies(ips) {
+		raddrs = append(raddrs, &net.UDPAddr{IP: ip.IP, Port: portNum, Zone: ip.Zone})
+	}
+	// When racing multiple connection attempts, connect the sockets,
+	// such that an attempt fails as soon as we receive an ICMP error (e.g. for a dead IPv6 address),
+	// and we can immediately start the next attempt.
+	// The socket of the attempt that succeeds is disconnected again.
+	connect := len(raddrs) > 1
+	tracingCtx := ctx
+	return dialParallel(ctx, raddrs, func(ctx context.Context, raddr *net.UDPAddr) (EarlyConnection, error) {
+		udpConn, err := listenUDPFor(ctx, raddr, conf)
+		if err != nil {
+			return nil, err
+		}
+		if connect {
+			if err := connectUDPConn(udpConn, raddr); err != nil {
+				udpConn.Close()
+				return nil, err
+			}
+		}
+		tr, err := setupTransport(udpConn, tlsConf, true)
+		if err != nil {
+			udpConn.Close()
+			return nil, err
+		}
+		// The context passed to this function is canceled when dialParallel returns.
+		// Use the parent context for the tracer, since that one lives on after the handshake.
+		conn, err := tr.dial(ctx, tracingCtx, raddr, addr, tlsConf, conf, use0RTT)
+		if err != nil {
+			tr.Close()
+			return nil, err
+		}
+		// From now on, an ICMP error (e.g. when the server restarts) must not close the transport.
+		if connect {
+			if err := disconnectUDPConn(udpConn); err != nil {
+				conn.CloseWithError(0, "")
+				return nil, err
+			}
+		}
+		return conn, nil
+	})
+}
+
+// lookupIPAddr resolves the host.
+// IP literals are returned without using the resolver.
+// As for net.Dial, an empty host refers to the local system.
+func lookupIPAddr(ctx context.Context, resolver *net.Resolver, host string) ([]net.IPAddr, error) {
+	if host == "" {
+		return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []net.IPAddr{{IP: ip.AsSlice(), Zone: ip.Zone()}}, nil
+	}
+	return resolver.LookupIPAddr(ctx, host)
+}
+
+func connectUDPConn(c *net.UDPConn, raddr *net.UDPAddr) error {
+	rawConn, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return connectUDP(rawConn, raddr)
+}
+
+func disconnectUDPConn(c *net.UDPConn) error {
+	rawConn, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return disconnectUDP(rawConn)
+}
+
+// listenUDPFor creates a UDP connection suitable for dialing the remote address.
+func listenUDPFor(ctx context.Context, raddr *net.UDPAddr, conf *Config) (*net.UDPConn, error) {
+	network, laddr := "udp", &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+	if raddr.IP.To4() == nil {
+		network, laddr = "udp6", &net.UDPAddr{IP: net.IPv6unspecified, Port: 0}
+	}
+	if conf == nil || conf.Control == nil {
+		return net.ListenUDP(network, laddr)
+	}
+	lc := net.ListenConfig{Control: conf.Control}
+	conn, err := lc.ListenPacket(ctx, network, laddr.String())
 	if err != nil {
-		tr.Close()
 		return nil, err
 	}
-	return conn, nil
+	return conn.(*net.UDPConn), nil
+}
+
+// interleaveAddrFamilies sorts the addresses such that IPv6 and IPv4 addresses alternate,
+// starting with an IPv6 address (if any), see section 4 of RFC 8305.
+// Apart from that, the order returned by the resolver is preserved.
+func interleaveAddrFamilies(ips []net.IPAddr) []net.IPAddr {
+	var v4, v6 []net.IPAddr
+	for _, ip := range ips {
+		if ip.IP.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+	sorted := make([]net.IPAddr, 0, len(ips))
+	for len(v4) > 0 || len(v6) > 0 {
+		if len(v6) > 0 {
+			sorted = append(sorted, v6[0])
+			v6 = v6[1:]
+		}
+		if len(v4) > 0 {
+			sorted = append(sorted, v4[0])
+			v4 = v4[1:]
+		}
+	}
+	return sorted
+}
+
+// dialParallel races connection attempts to the addresses.
+// Attempts are started in order, every connectionAttemptDelay, or as soon as the previous attempt failed.
+// The first connection that is established is returned,
+// all other connection attempts are canceled, and connections established in the meantime are closed.
+// If all attempts fail, the error of the first attempt is returned.
+func dialParallel(
+	ctx context.Context,
+	raddrs []*net.UDPAddr,
+	dialOne func(context.Context, *net.UDPAddr) (EarlyConnection, error),
+) (EarlyConnection, error) {
+	if len(raddrs) == 0 {
+		return nil, errors.New("quic: no addresses to dial")
+	}
+	if len(raddrs) == 1 {
+		return dialOne(ctx, raddrs[0])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		conn EarlyConnection
+		err  error
+	}
+	results := make(chan result, len(raddrs))
+	var next, pending int
+	startNext := func() {
+		raddr := raddrs[next]
+		next++
+		pending++
+		go func() {
+			conn, err := dialOne(ctx, raddr)
+			results <- result{conn: conn, err: err}
+		}()
+	}
+
+	startNext()
+	timer := time.NewTimer(connectionAttemptDelay)
+	defer func() { timer.Stop() }()
+	var firstErr error
+	for {
+		var timerChan <-chan time.Time
+		if next < len(raddrs) {
+			timerChan = timer.C
+		}
+		select {
+		case <-timerChan:
+			startNext()
+			timer = time.NewTimer(connectionAttemptDelay)
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				// Close connections that were established by other attempts in the meantime.
+				go func(n int) {
+					for i := 0; i < n; i++ {
+						if r := <-results; r.err == nil {
+							r.conn.CloseWithError(0, "")
+						}
+					}
+				}(pending)
+				return res.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if next < len(raddrs) {
+				timer.Stop()
+				startNext()
+				timer = time.NewTimer(connectionAttemptDelay)
+			} else if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
 }
 
 // DialEarly establishes a new 0-RTT QUIC connection to a server using a net.PacketConn.
@@ -134,7 +342,7 @@ func setupTransport(c net.PacketConn, tlsConf *tls.Config, createdPacketConn boo
 }
 
 func dial(
-	ctx context.Context,
+	ctx, tracingCtx context.Context,
 	conn sendConn,
 	connIDGenerator ConnectionIDGenerator,
 	packetHandlers packetHandlerManager,
@@ -151,7 +359,7 @@ func dial(
 
 	c.tracingID = nextConnTracingID()
 	if c.config.Tracer != nil {
-		c.tracer = c.config.Tracer(context.WithValue(ctx, ConnectionTracingKey, c.tracingID), protocol.PerspectiveClient, c.destConnID)
+		c.tracer = c.config.Tracer(context.WithValue(tracingCtx, ConnectionTracingKey, c.tracingID), protocol.PerspectiveClient, c.destConnID)
 	}
 	if c.tracer != nil && c.tracer.StartedConnection != nil {
 		c.tracer.StartedConnection(c.sendConn.LocalAddr(), c.sendConn.RemoteAddr(), c.srcConnID, c.destConnID)
@@ -0,0 +1,106 @@
+package quic
+
+import (
+	"net"
+	"sync"
+)
+
+type clientCount struct {
+	handshakes  int
+	connections int
+}
+
+// The clientLimiter limits the number of concurrent handshakes and connections per client.
+// Clients are identified by their IP address.
+// IPv6 addresses are grouped by their /64 prefix,
+// since a single host is usually assigned (at least) a /64.
+type clientLimiter struct {
+	maxHandshakes  int // if <= 0, the number of handshakes isn't limited
+	maxConnections int // if <= 0, the number of connections isn't limited
+
+	mutex   sync.Mutex
+	clients map[string]*clientCount
+}
+
+func newClientLimiter(maxHandshakes, maxConnections int) *clientLimiter {
+	return &clientLimiter{
+		maxHandshakes:  maxHandshakes,
+		maxConnections: maxConnections,
+		clients:        make(map[string]*clientCount),
+	}
+}
+
+func clientLimiterKey(addr net.Addr) string {
+	udpAddr, ok := toUDPAddr(addr)
+	if !ok {
+		return addr.String()
+	}
+	if ip := udpAddr.IP.To4(); ip != nil {
+		return ip.String()
+	}
+	return udpAddr.IP.Mask(net.CIDRMask(64, 128)).String()
+}
+
+// HandshakeLimitReached says if any more handshakes with this client would exceed the limit.
+func (l *clientLimiter) HandshakeLimitReached(addr net.Addr) bool {
+	if l.maxHandshakes <= 0 {
+		return false
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	c, ok := l.clients[clientLimiterKey(addr)]
+	return ok && c.handshakes >= l.maxHandshakes
+}
+
+// Add adds a new connection.
+// If countHandshake is set, the connection counts towards the handshake limit until HandshakeComplete is called.
+// It returns false if adding the connection would exceed one of the limits.
+func (l *clientLimiter) Add(addr net.Addr, countHandshake bool) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	key := clientLimiterKey(addr)
+	c, ok := l.clients[key]
+	if !ok {
+		c = &clientCount{}
+	}
+	if l.maxConnections > 0 && c.connections >= l.maxConnections {
+		return false
+	}
+	if countHandshake && l.maxHandshakes > 0 && c.handshakes >= l.maxHandshakes {
+		return false
+	}
+	c.connections++
+	if countHandshake {
+		c.handshakes++
+	}
+	l.clients[key] = c
+	return true
+}
+
+// HandshakeComplete is called when the handshake of a connection counted as a handshake completes (or fails).
+func (l *clientLimiter) HandshakeComplete(addr net.Addr) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if c, ok := l.clients[clientLimiterKey(addr)]; ok {
+		c.handshakes--
+	}
+}
+
+// Remove is called when a connection is closed.
+func (l *clientLimiter) Remove(addr net.Addr) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	key := clientLimiterKey(addr)
+	c, ok := l.clients[key]
+	if !ok {
+		return
+	}
+	c.connections--
+	if c.connections <= 0 {
+		delete(l.clients, key)
+	}
+}
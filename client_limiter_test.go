@@ -0,0 +1,74 @@
+package quic
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client Limiter", func() {
+	addr1 := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+	addr2 := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 2), Port: 1337}
+
+	It("limits the number of handshakes", func() {
+		l := newClientLimiter(2, 0)
+		Expect(l.Add(addr1, true)).To(BeTrue())
+		Expect(l.HandshakeLimitReached(addr1)).To(BeFalse())
+		Expect(l.Add(&net.UDPAddr{IP: addr1.IP, Port: 1234}, true)).To(BeTrue())
+		Expect(l.HandshakeLimitReached(addr1)).To(BeTrue())
+		Expect(l.Add(addr1, true)).To(BeFalse())
+		// connections that aren't counted as handshakes are not affected
+		Expect(l.Add(addr1, false)).To(BeTrue())
+		// other clients are not affected
+		Expect(l.HandshakeLimitReached(addr2)).To(BeFalse())
+		Expect(l.Add(addr2, true)).To(BeTrue())
+		l.HandshakeComplete(addr1)
+		Expect(l.HandshakeLimitReached(addr1)).To(BeFalse())
+		Expect(l.Add(addr1, true)).To(BeTrue())
+	})
+
+	It("limits the number of connections", func() {
+		l := newClientLimiter(0, 2)
+		Expect(l.Add(addr1, true)).To(BeTrue())
+		Expect(l.Add(addr1, false)).To(BeTrue())
+		Expect(l.HandshakeLimitReached(addr1)).To(BeFalse())
+		Expect(l.Add(addr1, false)).To(BeFalse())
+		Expect(l.Add(addr2, false)).To(BeTrue())
+		l.HandshakeComplete(addr1)
+		Expect(l.Add(addr1, false)).To(BeFalse())
+		l.Remove(addr1)
+		Expect(l.Add(addr1, false)).To(BeTrue())
+	})
+
+	It("forgets about clients once all connections are closed", func() {
+		l := newClientLimiter(1, 1)
+		Expect(l.Add(addr1, true)).To(BeTrue())
+		Expect(l.clients).To(HaveLen(1))
+		l.HandshakeComplete(addr1)
+		l.Remove(addr1)
+		Expect(l.clients).To(BeEmpty())
+	})
+
+	It("groups IPv6 addresses by their /64 prefix", func() {
+		l := newClientLimiter(0, 1)
+		Expect(l.Add(&net.UDPAddr{IP: net.ParseIP("2001:db8:1:2::1"), Port: 1337}, false)).To(BeTrue())
+		Expect(l.Add(&net.UDPAddr{IP: net.ParseIP("2001:db8:1:2::2"), Port: 1337}, false)).To(BeFalse())
+		Expect(l.Add(&net.UDPAddr{IP: net.ParseIP("2001:db8:1:3::1"), Port: 1337}, false)).To(BeTrue())
+	})
+
+	It("treats IPv4-mapped IPv6 addresses as IPv4 addresses", func() {
+		l := newClientLimiter(0, 1)
+		Expect(l.Add(&net.UDPAddr{IP: net.ParseIP("::ffff:192.168.0.1"), Port: 1337}, false)).To(BeTrue())
+		Expect(l.Add(addr1, false)).To(BeFalse())
+		Expect(l.Add(addr2, false)).To(BeTrue())
+	})
+
+	It("identifies proxied clients by their address", func() {
+		l := newClientLimiter(0, 1)
+		proxy := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443}
+		Expect(l.Add(&ProxiedAddr{Source: &net.UDPAddr{IP: addr1.IP, Port: 1}, Proxy: proxy}, false)).To(BeTrue())
+		Expect(l.Add(&ProxiedAddr{Source: &net.UDPAddr{IP: addr1.IP, Port: 2}, Proxy: proxy}, false)).To(BeFalse())
+		Expect(l.Add(&ProxiedAddr{Source: addr2, Proxy: proxy}, false)).To(BeTrue())
+	})
+})
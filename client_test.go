@@ -3,20 +3,82 @@ package quic
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/testdata"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 
+	"golang.org/x/net/dns/dnsmessage"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
 )
 
+// newStaticResolver returns a resolver that resolves every host to the given IPv4 addresses.
+func newStaticResolver(ips ...net.IP) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			c1, c2 := net.Pipe()
+			go func() {
+				defer c2.Close()
+				// the Go resolver uses the TCP framing on connections that are not a net.PacketConn
+				for {
+					var l [2]byte
+					if _, err := io.ReadFull(c2, l[:]); err != nil {
+						return
+					}
+					b := make([]byte, binary.BigEndian.Uint16(l[:]))
+					if _, err := io.ReadFull(c2, b); err != nil {
+						return
+					}
+					var msg dnsmessage.Message
+					if err := msg.Unpack(b); err != nil || len(msg.Questions) != 1 {
+						return
+					}
+					msg.Header.Response = true
+					if msg.Questions[0].Type == dnsmessage.TypeA {
+						for _, ip := range ips {
+							var a dnsmessage.AResource
+							copy(a.A[:], ip.To4())
+							msg.Answers = append(msg.Answers, dnsmessage.Resource{
+								Header: dnsmessage.ResourceHeader{
+									Name:  msg.Questions[0].Name,
+									Type:  dnsmessage.TypeA,
+									Class: dnsmessage.ClassINET,
+								},
+								Body: &a,
+							})
+						}
+					}
+					resp, err := msg.Pack()
+					if err != nil {
+						return
+					}
+					if _, err := c2.Write(binary.BigEndian.AppendUint16(nil, uint16(len(resp)))); err != nil {
+						return
+					}
+					if _, err := c2.Write(resp); err != nil {
+						return
+					}
+				}
+			}()
+			return c1, nil
+		},
+	}
+}
+
 type nullMultiplexer struct{}
 
 func (n nullMultiplexer) AddConn(indexableConn)          {}
@@ -357,5 +419,273 @@ var _ = Describe("Client", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(counter).To(Equal(2))
 		})
+
+		It("uses the configured resolver", func() {
+			var called bool
+			resolver := &net.Resolver{
+				PreferGo: true,
+				Dial: func(context.Context, string, string) (net.Conn, error) {
+					called = true
+					return nil, errors.New("custom resolver")
+				},
+			}
+			_, err := DialAddr(context.Background(), "quic-go.invalid:443", tlsConf, &Config{Resolver: resolver})
+			Expect(err).To(MatchError(ContainSubstring("custom resolver")))
+			Expect(called).To(BeTrue())
+		})
+
+		It("calls the Control function when creating the socket", func() {
+			var network string
+			_, err := DialAddr(context.Background(), "127.0.0.1:443", tlsConf, &Config{
+				Control: func(n, _ string, _ syscall.RawConn) error {
+					network = n
+					return errors.New("control error")
+				},
+			})
+			Expect(err).To(MatchError(ContainSubstring("control error")))
+			Expect(network).To(HavePrefix("udp"))
+		})
+	})
+
+	Context("Happy Eyeballs", func() {
+		var origConnectionAttemptDelay time.Duration
+
+		BeforeEach(func() {
+			origConnectionAttemptDelay = connectionAttemptDelay
+			connectionAttemptDelay = 50 * time.Millisecond
+		})
+
+		AfterEach(func() {
+			connectionAttemptDelay = origConnectionAttemptDelay
+		})
+
+		ipv6Addr1 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 443}
+		ipv6Addr2 := &net.UDPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443}
+		ipv4Addr1 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 443}
+		ipv4Addr2 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 443}
+
+		It("interleaves IPv6 and IPv4 addresses", func() {
+			ips := []net.IPAddr{{IP: ipv4Addr1.IP}, {IP: ipv4Addr2.IP}, {IP: ipv6Addr1.IP}, {IP: ipv6Addr2.IP}}
+			Expect(interleaveAddrFamilies(ips)).To(Equal([]net.IPAddr{
+				{IP: ipv6Addr1.IP},
+				{IP: ipv4Addr1.IP},
+				{IP: ipv6Addr2.IP},
+				{IP: ipv4Addr2.IP},
+			}))
+			Expect(interleaveAddrFamilies(ips[:2])).To(Equal(ips[:2]))
+		})
+
+		It("doesn't use the resolver for IP literals and empty hosts", func() {
+			resolver := &net.Resolver{
+				PreferGo: true,
+				Dial: func(context.Context, string, string) (net.Conn, error) {
+					Fail("resolver should not be used")
+					return nil, errors.New("unexpected lookup")
+				},
+			}
+			ips, err := lookupIPAddr(context.Background(), resolver, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ips).To(Equal([]net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}))
+			ips, err = lookupIPAddr(context.Background(), resolver, "192.0.2.1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ips).To(HaveLen(1))
+			Expect(ips[0].IP.Equal(ipv4Addr1.IP)).To(BeTrue())
+			ips, err = lookupIPAddr(context.Background(), resolver, "fe80::1%eth0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ips).To(Equal([]net.IPAddr{{IP: net.ParseIP("fe80::1"), Zone: "eth0"}}))
+		})
+
+		It("returns the cancellation cause when the lookup is canceled", func() {
+			resolver := &net.Resolver{
+				PreferGo: true,
+				Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				},
+			}
+			ctx, cancel := context.WithCancelCause(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				_, err := DialAddr(ctx, "quic-go.example:443", &tls.Config{}, &Config{Resolver: resolver})
+				errChan <- err
+			}()
+			Consistently(errChan, scaleDuration(20*time.Millisecond)).ShouldNot(Receive())
+			cancel(errors.New("application cancelled"))
+			Eventually(errChan).Should(Receive(MatchError("application cancelled")))
+		})
+
+		It("dials an address with an empty host", func() {
+			tlsConf := testdata.GetTLSConfig()
+			tlsConf.NextProtos = []string{"test"}
+			ln, err := ListenAddr("localhost:0", tlsConf, nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			conn, err := DialAddr(
+				ctx,
+				fmt.Sprintf(":%d", ln.Addr().(*net.UDPAddr).Port),
+				&tls.Config{RootCAs: testdata.GetRootCA(), ServerName: "localhost", NextProtos: []string{"test"}},
+				nil,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			conn.CloseWithError(0, "")
+		})
+
+		It("doesn't close the connection on ICMP errors after the handshake", func() {
+			tlsConf := testdata.GetTLSConfig()
+			tlsConf.NextProtos = []string{"test"}
+			ln, err := ListenAddr("127.0.0.1:0", tlsConf, nil)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+			serverAddr := ln.Addr().(*net.UDPAddr)
+
+			// relay packets between the client and the server, such that we can make the server's address unreachable
+			relay := func(c *net.UDPConn) {
+				var clientAddr *net.UDPAddr
+				b := make([]byte, protocol.MaxPacketBufferSize)
+				for {
+					n, addr, err := c.ReadFromUDP(b)
+					if err != nil {
+						return
+					}
+					if addr.Port == serverAddr.Port {
+						c.WriteToUDP(b[:n], clientAddr)
+					} else {
+						clientAddr = addr
+						c.WriteToUDP(b[:n], serverAddr)
+					}
+				}
+			}
+			relayConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+			relayAddr := relayConn.LocalAddr().(*net.UDPAddr)
+			go relay(relayConn)
+
+			// resolve to multiple addresses, such that the sockets are connected while racing
+			resolver := newStaticResolver(net.IPv4(127, 0, 0, 1), net.IPv4(127, 0, 0, 2))
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, err := DialAddr(
+				ctx,
+				fmt.Sprintf("quic-go.example:%d", relayAddr.Port),
+				&tls.Config{RootCAs: testdata.GetRootCA(), ServerName: "localhost", NextProtos: []string{"test"}},
+				&Config{Resolver: resolver},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			sconn, err := ln.Accept(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Nobody is listening on the relay's address now.
+			// The next packet sent by the client triggers an ICMP port unreachable.
+			Expect(relayConn.Close()).To(Succeed())
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			Consistently(conn.Context().Done(), scaleDuration(50*time.Millisecond)).ShouldNot(BeClosed())
+
+			relayConn, err = net.ListenUDP("udp", relayAddr)
+			Expect(err).ToNot(HaveOccurred())
+			defer relayConn.Close()
+			go relay(relayConn)
+			sstr, err := sconn.AcceptStream(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(sstr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foobar")))
+		})
+
+		It("dials a single address", func() {
+			conn := NewMockQUICConn(mockCtrl)
+			c, err := dialParallel(context.Background(), []*net.UDPAddr{ipv4Addr1}, func(_ context.Context, addr *net.UDPAddr) (EarlyConnection, error) {
+				Expect(addr).To(Equal(ipv4Addr1))
+				return conn, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(conn))
+		})
+
+		It("starts the next attempt after the connection attempt delay", func() {
+			conn := NewMockQUICConn(mockCtrl)
+			start := time.Now()
+			c, err := dialParallel(context.Background(), []*net.UDPAddr{ipv6Addr1, ipv4Addr1}, func(ctx context.Context, addr *net.UDPAddr) (EarlyConnection, error) {
+				if addr == ipv6Addr1 {
+					// a broken IPv6 path
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				Expect(time.Since(start)).To(BeNumerically(">=", connectionAttemptDelay))
+				return conn, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(conn))
+		})
+
+		It("starts the next attempt as soon as the previous attempt failed", func() {
+			conn := NewMockQUICConn(mockCtrl)
+			start := time.Now()
+			c, err := dialParallel(context.Background(), []*net.UDPAddr{ipv6Addr1, ipv4Addr1}, func(ctx context.Context, addr *net.UDPAddr) (EarlyConnection, error) {
+				if addr == ipv6Addr1 {
+					return nil, errors.New("no route to host")
+				}
+				return conn, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(conn))
+			Expect(time.Since(start)).To(BeNumerically("<", connectionAttemptDelay))
+		})
+
+		It("closes connections established by other attempts", func() {
+			conn1 := NewMockQUICConn(mockCtrl)
+			conn2 := NewMockQUICConn(mockCtrl)
+			closed := make(chan struct{})
+			conn2.EXPECT().CloseWithError(ApplicationErrorCode(0), "").Do(func(ApplicationErrorCode, string) error {
+				close(closed)
+				return nil
+			})
+			firstDone := make(chan struct{})
+			c, err := dialParallel(context.Background(), []*net.UDPAddr{ipv6Addr1, ipv4Addr1}, func(ctx context.Context, addr *net.UDPAddr) (EarlyConnection, error) {
+				if addr == ipv6Addr1 {
+					defer close(firstDone)
+					time.Sleep(connectionAttemptDelay * 2)
+					return conn1, nil
+				}
+				<-firstDone
+				return conn2, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(conn1))
+			Eventually(closed).Should(BeClosed())
+		})
+
+		It("returns the error of the first attempt if all attempts fail", func() {
+			_, err := dialParallel(context.Background(), []*net.UDPAddr{ipv6Addr1, ipv4Addr1, ipv6Addr2}, func(ctx context.Context, addr *net.UDPAddr) (EarlyConnection, error) {
+				if addr == ipv6Addr1 {
+					return nil, errors.New("first error")
+				}
+				return nil, errors.New("another error")
+			})
+			Expect(err).To(MatchError("first error"))
+		})
+
+		It("cancels all attempts when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			var attempts int32
+			go func() {
+				_, err := dialParallel(ctx, []*net.UDPAddr{ipv6Addr1, ipv4Addr1}, func(ctx context.Context, addr *net.UDPAddr) (EarlyConnection, error) {
+					atomic.AddInt32(&attempts, 1)
+					<-ctx.Done()
+					return nil, ctx.Err()
+				})
+				errChan <- err
+			}()
+			Eventually(func() int32 { return atomic.LoadInt32(&attempts) }).Should(BeEquivalentTo(2))
+			cancel()
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+		})
 	})
 })
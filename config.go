@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"time"
@@ -40,6 +41,32 @@ func validateConfig(config *Config) error {
 	if config.MaxConnectionReceiveWindow > quicvarint.Max {
 		config.MaxConnectionReceiveWindow = quicvarint.Max
 	}
+	// max_ack_delay is encoded in milliseconds
+	if r := config.MaxAckDelay % time.Millisecond; r != 0 {
+		config.MaxAckDelay += time.Millisecond - r
+	}
+	if config.MaxAckDelay > protocol.MaxMaxAckDelay-protocol.TimerGranularity {
+		config.MaxAckDelay = protocol.MaxMaxAckDelay - protocol.TimerGranularity
+	}
+	if config.MaxUDPPayloadSize != 0 && config.MaxUDPPayloadSize < protocol.MinInitialPacketSize {
+		return fmt.Errorf("invalid max UDP payload size: %d (minimum %d)", config.MaxUDPPayloadSize, protocol.MinInitialPacketSize)
+	}
+	if config.MaxUDPPayloadSize > protocol.MaxPacketBufferSize {
+		config.MaxUDPPayloadSize = protocol.MaxPacketBufferSize
+	}
+	if config.MaxDatagramFrameSize > protocol.MaxPacketBufferSize {
+		config.MaxDatagramFrameSize = protocol.MaxPacketBufferSize
+	}
+	if config.DSCP > 63 {
+		return fmt.Errorf("invalid DSCP: %d", config.DSCP)
+	}
+	for _, id := range config.AllowedCipherSuites {
+		switch id {
+		case tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384, tls.TLS_CHACHA20_POLY1305_SHA256:
+		default:
+			return fmt.Errorf("invalid TLS 1.3 cipher suite: %#x", id)
+		}
+	}
 	// check that all QUIC versions are actually supported
 	for _, v := range config.Versions {
 		if !protocol.IsValidVersion(v) {
@@ -77,6 +104,18 @@ func populateConfig(config *Config) *Config {
 	if config.MaxIdleTimeout != 0 {
 		idleTimeout = config.MaxIdleTimeout
 	}
+	maxAckDelay := protocol.MaxAckDelay
+	if config.MaxAckDelay > 0 {
+		maxAckDelay = config.MaxAckDelay
+	}
+	packetsBeforeAck := protocol.PacketsBeforeAck
+	if config.PacketsBeforeAck > 0 {
+		packetsBeforeAck = config.PacketsBeforeAck
+	}
+	maxDatagramFrameSize := config.MaxDatagramFrameSize
+	if maxDatagramFrameSize == 0 {
+		maxDatagramFrameSize = uint16(protocol.MaxDatagramFrameSize)
+	}
 	initialStreamReceiveWindow := config.InitialStreamReceiveWindow
 	if initialStreamReceiveWindow == 0 {
 		initialStreamReceiveWindow = protocol.DefaultInitialMaxStreamData
@@ -109,9 +148,17 @@ func populateConfig(config *Config) *Config {
 	return &Config{
 		GetConfigForClient:             config.GetConfigForClient,
 		Versions:                       versions,
+		AcceptVersion:                  config.AcceptVersion,
 		HandshakeIdleTimeout:           handshakeIdleTimeout,
 		MaxIdleTimeout:                 idleTimeout,
+		InitialRTT:                     config.InitialRTT,
+		MaxAckDelay:                    maxAckDelay,
+		PacketsBeforeAck:               packetsBeforeAck,
 		RequireAddressValidation:       config.RequireAddressValidation,
+		AcceptClient:                   config.AcceptClient,
+		MaxHandshakesPerIP:             config.MaxHandshakesPerIP,
+		MaxConnectionsPerIP:            config.MaxConnectionsPerIP,
+		SendRetryOnHandshakeLimit:      config.SendRetryOnHandshakeLimit,
 		KeepAlivePeriod:                config.KeepAlivePeriod,
 		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
 		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
@@ -121,9 +168,18 @@ func populateConfig(config *Config) *Config {
 		MaxIncomingStreams:             maxIncomingStreams,
 		MaxIncomingUniStreams:          maxIncomingUniStreams,
 		TokenStore:                     config.TokenStore,
+		Resolver:                       config.Resolver,
 		EnableDatagrams:                config.EnableDatagrams,
+		MaxDatagramFrameSize:           maxDatagramFrameSize,
+		DatagramSendQueueLen:           config.DatagramSendQueueLen,
+		DatagramDropped:                config.DatagramDropped,
 		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
+		MaxUDPPayloadSize:              config.MaxUDPPayloadSize,
+		EnableSpinBit:                  config.EnableSpinBit,
+		DSCP:                           config.DSCP,
+		Control:                        config.Control,
 		Allow0RTT:                      config.Allow0RTT,
+		AllowedCipherSuites:            config.AllowedCipherSuites,
 		Tracer:                         config.Tracer,
 	}
 }
@@ -2,10 +2,12 @@ package quic
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"reflect"
+	"syscall"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -51,6 +53,50 @@ var _ = Describe("Config", func() {
 			Expect(conf.MaxStreamReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 			Expect(conf.MaxConnectionReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 		})
+
+		It("clips too large values for the max ack delay", func() {
+			conf := &Config{MaxAckDelay: time.Hour}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxAckDelay).To(Equal(protocol.MaxMaxAckDelay - protocol.TimerGranularity))
+		})
+
+		It("rounds the max ack delay up to whole milliseconds", func() {
+			conf := &Config{MaxAckDelay: 24*time.Millisecond + 100*time.Microsecond}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxAckDelay).To(Equal(25 * time.Millisecond))
+			conf = &Config{MaxAckDelay: 500 * time.Microsecond}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxAckDelay).To(Equal(time.Millisecond))
+			conf = &Config{MaxAckDelay: 10 * time.Millisecond}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxAckDelay).To(Equal(10 * time.Millisecond))
+		})
+
+		It("clips too large values for the max UDP payload size", func() {
+			conf := &Config{MaxUDPPayloadSize: 9000}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxUDPPayloadSize).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+		})
+
+		It("errors on too small values for the max UDP payload size", func() {
+			Expect(validateConfig(&Config{MaxUDPPayloadSize: 1199})).To(MatchError("invalid max UDP payload size: 1199 (minimum 1200)"))
+		})
+
+		It("clips too large values for the max DATAGRAM frame size", func() {
+			conf := &Config{MaxDatagramFrameSize: 9000}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxDatagramFrameSize).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+		})
+
+		It("errors on invalid DSCP values", func() {
+			Expect(validateConfig(&Config{DSCP: 63})).To(Succeed())
+			Expect(validateConfig(&Config{DSCP: 64})).To(MatchError("invalid DSCP: 64"))
+		})
+
+		It("errors on cipher suites that aren't TLS 1.3 cipher suites", func() {
+			Expect(validateConfig(&Config{AllowedCipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256, tls.TLS_CHACHA20_POLY1305_SHA256}})).To(Succeed())
+			Expect(validateConfig(&Config{AllowedCipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}})).To(MatchError("invalid TLS 1.3 cipher suite: 0xc02f"))
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -66,7 +112,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+			case "GetConfigForClient", "AcceptVersion", "RequireAddressValidation", "AcceptClient", "GetLogWriter", "AllowConnectionWindowIncrease", "DatagramDropped", "Tracer", "Control":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]VersionNumber{1, 2, 3}))
@@ -78,8 +124,22 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(time.Second))
 			case "MaxIdleTimeout":
 				f.Set(reflect.ValueOf(time.Hour))
+			case "InitialRTT":
+				f.Set(reflect.ValueOf(time.Minute))
+			case "MaxAckDelay":
+				f.Set(reflect.ValueOf(42 * time.Millisecond))
+			case "PacketsBeforeAck":
+				f.Set(reflect.ValueOf(10))
+			case "MaxHandshakesPerIP":
+				f.Set(reflect.ValueOf(13))
+			case "MaxConnectionsPerIP":
+				f.Set(reflect.ValueOf(14))
+			case "SendRetryOnHandshakeLimit":
+				f.Set(reflect.ValueOf(true))
 			case "TokenStore":
 				f.Set(reflect.ValueOf(NewLRUTokenStore(2, 3)))
+			case "Resolver":
+				f.Set(reflect.ValueOf(&net.Resolver{PreferGo: true}))
 			case "InitialStreamReceiveWindow":
 				f.Set(reflect.ValueOf(uint64(1234)))
 			case "MaxStreamReceiveWindow":
@@ -98,12 +158,24 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(time.Second))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "MaxDatagramFrameSize":
+				f.Set(reflect.ValueOf(uint16(1000)))
+			case "DatagramSendQueueLen":
+				f.Set(reflect.ValueOf(20))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "EnableSpinBit":
+				f.Set(reflect.ValueOf(true))
+			case "MaxUDPPayloadSize":
+				f.Set(reflect.ValueOf(uint16(1400)))
+			case "DSCP":
+				f.Set(reflect.ValueOf(uint8(46)))
 			case "Allow0RTT":
 				f.Set(reflect.ValueOf(true))
+			case "AllowedCipherSuites":
+				f.Set(reflect.ValueOf([]uint16{tls.TLS_AES_128_GCM_SHA256}))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -118,25 +190,34 @@ var _ = Describe("Config", func() {
 
 	Context("cloning", func() {
 		It("clones function fields", func() {
-			var calledAddrValidation, calledAllowConnectionWindowIncrease, calledTracer bool
+			var calledAddrValidation, calledAcceptClient, calledAcceptVersion, calledAllowConnectionWindowIncrease, calledTracer, calledControl bool
 			c1 := &Config{
 				GetConfigForClient:            func(info *ClientHelloInfo) (*Config, error) { return nil, errors.New("nope") },
+				AcceptVersion:                 func(net.Addr, VersionNumber) bool { calledAcceptVersion = true; return true },
 				AllowConnectionWindowIncrease: func(Connection, uint64) bool { calledAllowConnectionWindowIncrease = true; return true },
 				RequireAddressValidation:      func(net.Addr) bool { calledAddrValidation = true; return true },
+				AcceptClient:                  func(net.Addr) bool { calledAcceptClient = true; return true },
 				Tracer: func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer {
 					calledTracer = true
 					return nil
 				},
+				Control: func(string, string, syscall.RawConn) error { calledControl = true; return nil },
 			}
 			c2 := c1.Clone()
 			c2.RequireAddressValidation(&net.UDPAddr{})
 			Expect(calledAddrValidation).To(BeTrue())
+			c2.AcceptClient(&net.UDPAddr{})
+			Expect(calledAcceptClient).To(BeTrue())
+			c2.AcceptVersion(&net.UDPAddr{}, protocol.Version1)
+			Expect(calledAcceptVersion).To(BeTrue())
 			c2.AllowConnectionWindowIncrease(nil, 1234)
 			Expect(calledAllowConnectionWindowIncrease).To(BeTrue())
 			_, err := c2.GetConfigForClient(&ClientHelloInfo{})
 			Expect(err).To(MatchError("nope"))
 			c2.Tracer(context.Background(), logging.PerspectiveClient, protocol.ConnectionID{})
 			Expect(calledTracer).To(BeTrue())
+			Expect(c2.Control("udp", "", nil)).To(Succeed())
+			Expect(calledControl).To(BeTrue())
 		})
 
 		It("clones non-function fields", func() {
@@ -160,12 +241,18 @@ var _ = Describe("Config", func() {
 
 	Context("populating", func() {
 		It("populates function fields", func() {
-			var calledAddrValidation bool
+			var calledAddrValidation, calledAcceptClient, calledAcceptVersion bool
 			c1 := &Config{}
 			c1.RequireAddressValidation = func(net.Addr) bool { calledAddrValidation = true; return true }
+			c1.AcceptClient = func(net.Addr) bool { calledAcceptClient = true; return true }
+			c1.AcceptVersion = func(net.Addr, VersionNumber) bool { calledAcceptVersion = true; return true }
 			c2 := populateConfig(c1)
 			c2.RequireAddressValidation(&net.UDPAddr{})
 			Expect(calledAddrValidation).To(BeTrue())
+			c2.AcceptClient(&net.UDPAddr{})
+			Expect(calledAcceptClient).To(BeTrue())
+			c2.AcceptVersion(&net.UDPAddr{}, protocol.Version1)
+			Expect(calledAcceptVersion).To(BeTrue())
 		})
 
 		It("copies non-function fields", func() {
@@ -185,6 +272,8 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
 			Expect(c.GetConfigForClient).To(BeNil())
+			Expect(c.MaxAckDelay).To(Equal(protocol.MaxAckDelay))
+			Expect(c.PacketsBeforeAck).To(Equal(protocol.PacketsBeforeAck))
 		})
 
 		It("populates empty fields with default values, for the server", func() {
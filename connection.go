@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"reflect"
 	"sync"
@@ -204,9 +205,17 @@ type connection struct {
 
 	datagramQueue *datagramQueue
 
+	// spinBitEnabled says if the latency spin bit is used on this connection
+	spinBitEnabled bool
+	// the largest packet number of all 1-RTT packets received, used to determine the spin bit
+	largestRcvdSpinPN protocol.PacketNumber
+
 	connStateMutex sync.Mutex
 	connState      ConnectionState
 
+	pingMutex    sync.Mutex
+	pendingPings []chan struct{} // closed when a PING frame is acknowledged
+
 	logID  string
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
@@ -275,15 +284,17 @@ var newConnection = func(
 	s.ctx, s.ctxCancel = context.WithCancelCause(context.WithValue(context.Background(), ConnectionTracingKey, tracingID))
 	s.sentPacketHandler, s.receivedPacketHandler = ackhandler.NewAckHandler(
 		0,
-		getMaxPacketSize(s.conn.RemoteAddr()),
+		getInitialMaxDatagramSize(s.conn.RemoteAddr(), s.config),
 		s.rttStats,
 		clientAddressValidated,
 		s.conn.capabilities().ECN,
+		s.config.MaxAckDelay,
+		s.config.PacketsBeforeAck,
 		s.perspective,
 		s.tracer,
 		s.logger,
 	)
-	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(s.conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize)
+	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getInitialPacketSize(s.conn.RemoteAddr(), s.config), s.sentPacketHandler.SetMaxDatagramSize)
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiLocal:   protocol.ByteCount(s.config.InitialStreamReceiveWindow),
 		InitialMaxStreamDataBidiRemote:  protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -292,7 +303,7 @@ var newConnection = func(
 		MaxIdleTimeout:                  s.config.MaxIdleTimeout,
 		MaxBidiStreamNum:                protocol.StreamNum(s.config.MaxIncomingStreams),
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
-		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
+		MaxAckDelay:                     s.config.MaxAckDelay + protocol.TimerGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
 		DisableActiveMigration:          true,
 		StatelessResetToken:             &statelessResetToken,
@@ -307,10 +318,13 @@ var newConnection = func(
 		RetrySourceConnectionID:   retrySrcConnID,
 	}
 	if s.config.EnableDatagrams {
-		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
+		params.MaxDatagramFrameSize = protocol.ByteCount(s.config.MaxDatagramFrameSize)
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.MaxUDPPayloadSize != 0 {
+		params.MaxUDPPayloadSize = protocol.ByteCount(s.config.MaxUDPPayloadSize)
+	}
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -320,6 +334,7 @@ var newConnection = func(
 		conn.RemoteAddr(),
 		params,
 		tlsConf,
+		conf.AllowedCipherSuites,
 		conf.Allow0RTT,
 		s.rttStats,
 		tracer,
@@ -328,6 +343,7 @@ var newConnection = func(
 	)
 	s.cryptoStreamHandler = cs
 	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective)
+	s.initSpinBit(rand.Intn)
 	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
 	s.cryptoStreamManager = newCryptoStreamManager(cs, s.initialStream, s.handshakeStream, s.oneRTTStream)
 	return s
@@ -384,15 +400,17 @@ var newClientConnection = func(
 	s.ctx, s.ctxCancel = context.WithCancelCause(context.WithValue(context.Background(), ConnectionTracingKey, tracingID))
 	s.sentPacketHandler, s.receivedPacketHandler = ackhandler.NewAckHandler(
 		initialPacketNumber,
-		getMaxPacketSize(s.conn.RemoteAddr()),
+		getInitialMaxDatagramSize(s.conn.RemoteAddr(), s.config),
 		s.rttStats,
 		false, // has no effect
 		s.conn.capabilities().ECN,
+		s.config.MaxAckDelay,
+		s.config.PacketsBeforeAck,
 		s.perspective,
 		s.tracer,
 		s.logger,
 	)
-	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(s.conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize)
+	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getInitialPacketSize(s.conn.RemoteAddr(), s.config), s.sentPacketHandler.SetMaxDatagramSize)
 	oneRTTStream := newCryptoStream()
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiRemote: protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -402,7 +420,7 @@ var newClientConnection = func(
 		MaxIdleTimeout:                 s.config.MaxIdleTimeout,
 		MaxBidiStreamNum:               protocol.StreamNum(s.config.MaxIncomingStreams),
 		MaxUniStreamNum:                protocol.StreamNum(s.config.MaxIncomingUniStreams),
-		MaxAckDelay:                    protocol.MaxAckDelayInclGranularity,
+		MaxAckDelay:                    s.config.MaxAckDelay + protocol.TimerGranularity,
 		AckDelayExponent:               protocol.AckDelayExponent,
 		DisableActiveMigration:         true,
 		// For interoperability with quic-go versions before May 2023, this value must be set to a value
@@ -414,10 +432,13 @@ var newClientConnection = func(
 		InitialSourceConnectionID: srcConnID,
 	}
 	if s.config.EnableDatagrams {
-		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
+		params.MaxDatagramFrameSize = protocol.ByteCount(s.config.MaxDatagramFrameSize)
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.MaxUDPPayloadSize != 0 {
+		params.MaxUDPPayloadSize = protocol.ByteCount(s.config.MaxUDPPayloadSize)
+	}
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -425,6 +446,7 @@ var newClientConnection = func(
 		destConnID,
 		params,
 		tlsConf,
+		conf.AllowedCipherSuites,
 		enable0RTT,
 		s.rttStats,
 		tracer,
@@ -435,6 +457,7 @@ var newClientConnection = func(
 	s.cryptoStreamManager = newCryptoStreamManager(cs, s.initialStream, s.handshakeStream, oneRTTStream)
 	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
 	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective)
+	s.initSpinBit(rand.Intn)
 	if len(tlsConf.ServerName) > 0 {
 		s.tokenStoreKey = tlsConf.ServerName
 	} else {
@@ -452,9 +475,12 @@ func (s *connection) preSetup() {
 	s.initialStream = newCryptoStream()
 	s.handshakeStream = newCryptoStream()
 	s.sendQueue = newSendQueue(s.conn)
-	s.retransmissionQueue = newRetransmissionQueue()
+	s.retransmissionQueue = newRetransmissionQueue(s.onPingAcked)
 	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams)
 	s.rttStats = &utils.RTTStats{}
+	if s.config.InitialRTT > 0 {
+		s.rttStats.SetInitialRTT(s.config.InitialRTT)
+	}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
 		protocol.ByteCount(s.config.MaxConnectionReceiveWindow),
@@ -487,8 +513,13 @@ func (s *connection) preSetup() {
 	s.creationTime = now
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
-	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+	var datagramDropped func([]byte)
+	if s.config.DatagramDropped != nil {
+		datagramDropped = func(data []byte) { s.config.DatagramDropped(s, data) }
+	}
+	s.datagramQueue = newDatagramQueue(s.scheduleSending, datagramDropped, s.config.DatagramSendQueueLen, s.logger)
 	s.connState.Version = s.version
+	s.largestRcvdSpinPN = protocol.InvalidPacketNumber
 }
 
 // run the connection main loop
@@ -669,6 +700,23 @@ func (s *connection) supportsDatagrams() bool {
 	return s.peerParams.MaxDatagramFrameSize > 0
 }
 
+func (s *connection) peerTransportParameters() TransportParameters {
+	params := TransportParameters{
+		MaxIdleTimeout:                 s.peerParams.MaxIdleTimeout,
+		MaxUDPPayloadSize:              int64(s.peerParams.MaxUDPPayloadSize),
+		InitialMaxData:                 int64(s.peerParams.InitialMaxData),
+		InitialMaxStreamDataBidiLocal:  int64(s.peerParams.InitialMaxStreamDataBidiLocal),
+		InitialMaxStreamDataBidiRemote: int64(s.peerParams.InitialMaxStreamDataBidiRemote),
+		InitialMaxStreamDataUni:        int64(s.peerParams.InitialMaxStreamDataUni),
+		MaxBidiStreams:                 int64(s.peerParams.MaxBidiStreamNum),
+		MaxUniStreams:                  int64(s.peerParams.MaxUniStreamNum),
+	}
+	if s.supportsDatagrams() {
+		params.MaxDatagramFrameSize = int64(s.peerParams.MaxDatagramFrameSize)
+	}
+	return params
+}
+
 func (s *connection) ConnectionState() ConnectionState {
 	s.connStateMutex.Lock()
 	defer s.connStateMutex.Unlock()
@@ -771,7 +819,8 @@ func (s *connection) handleHandshakeConfirmed() error {
 	s.sentPacketHandler.SetHandshakeConfirmed()
 	s.cryptoStreamHandler.SetHandshakeConfirmed()
 
-	if !s.config.DisablePathMTUDiscovery && s.conn.capabilities().DF {
+	// If the MaxUDPPayloadSize is configured, packets are already sent at that size.
+	if !s.config.DisablePathMTUDiscovery && s.config.MaxUDPPayloadSize == 0 && s.conn.capabilities().DF {
 		maxPacketSize := s.peerParams.MaxUDPPayloadSize
 		if maxPacketSize == 0 {
 			maxPacketSize = protocol.MaxByteCount
@@ -899,6 +948,10 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 		return false
 	}
 
+	if s.spinBitEnabled && pn > s.largestRcvdSpinPN {
+		s.updateSpinBit(pn, p.data[0]&0x20 > 0)
+	}
+
 	var log func([]logging.Frame)
 	if s.tracer != nil && s.tracer.ReceivedShortHeaderPacket != nil {
 		log = func(frames []logging.Frame) {
@@ -922,6 +975,27 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 	return true
 }
 
+// initSpinBit decides if the latency spin bit is used on this connection.
+// RFC 9000 requires disabling the spin bit for at least one in every 16 connections.
+// Connections that don't spin use a random value, such that they can't be told apart by on-path observers.
+func (s *connection) initSpinBit(intn func(int) int) {
+	s.spinBitEnabled = s.config.EnableSpinBit && intn(16) != 0
+	if !s.spinBitEnabled {
+		s.packer.SetSpinBit(intn(2) == 1)
+	}
+}
+
+// updateSpinBit updates the spin bit value when receiving a 1-RTT packet with a higher packet number.
+// The server reflects the spin bit value it received, the client inverts it.
+// The spin bit isn't covered by header protection.
+func (s *connection) updateSpinBit(pn protocol.PacketNumber, spin bool) {
+	s.largestRcvdSpinPN = pn
+	if s.perspective == protocol.PerspectiveClient {
+		spin = !spin
+	}
+	s.packer.SetSpinBit(spin)
+}
+
 func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -1522,7 +1596,7 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 }
 
 func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
-	if f.Length(s.version) > protocol.MaxDatagramFrameSize {
+	if f.Length(s.version) > protocol.ByteCount(s.config.MaxDatagramFrameSize) {
 		return &qerr.TransportError{
 			ErrorCode:    qerr.ProtocolViolation,
 			ErrorMessage: "DATAGRAM frame too large",
@@ -1680,6 +1754,7 @@ func (s *connection) restoreTransportParameters(params *wire.TransportParameters
 	s.streamsMap.UpdateLimits(params)
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
+	s.connState.PeerTransportParameters = s.peerTransportParameters()
 	s.connStateMutex.Unlock()
 }
 
@@ -1702,6 +1777,15 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 	}
 
 	s.peerParams = params
+	// If the MaxUDPPayloadSize is configured, we don't do Path MTU Discovery,
+	// and started sending packets of the configured size. Make sure that we respect the peer's limit.
+	if s.config.MaxUDPPayloadSize != 0 {
+		if params.MaxUDPPayloadSize < s.mtuDiscoverer.CurrentSize() {
+			s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, params.MaxUDPPayloadSize, s.sentPacketHandler.SetMaxDatagramSize)
+		}
+		// The congestion controller was initialized with the smallest allowed datagram size.
+		s.sentPacketHandler.SetMaxDatagramSize(s.mtuDiscoverer.CurrentSize())
+	}
 	// On the client side we have to wait for handshake completion.
 	// During a 0-RTT connection, we are only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveServer {
@@ -1713,6 +1797,7 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
+	s.connState.PeerTransportParameters = s.peerTransportParameters()
 	s.connStateMutex.Unlock()
 	return nil
 }
@@ -2349,8 +2434,8 @@ func (s *connection) SendMessage(p []byte) error {
 	}
 
 	f := &wire.DatagramFrame{DataLenPresent: true}
-	if protocol.ByteCount(len(p)) > f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version) {
-		return errors.New("message too large")
+	if maxDataLen := f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version); protocol.ByteCount(len(p)) > maxDataLen {
+		return &DatagramTooLargeError{MaxDataLen: int64(maxDataLen)}
 	}
 	f.Data = make([]byte, len(p))
 	copy(f.Data, p)
@@ -2364,6 +2449,47 @@ func (s *connection) ReceiveMessage(ctx context.Context) ([]byte, error) {
 	return s.datagramQueue.Receive(ctx)
 }
 
+func (s *connection) Ping(ctx context.Context) error {
+	acked := make(chan struct{})
+	s.pingMutex.Lock()
+	s.pendingPings = append(s.pendingPings, acked)
+	s.pingMutex.Unlock()
+	s.queueControlFrame(&wire.PingFrame{})
+
+	select {
+	case <-acked:
+		return nil
+	case <-ctx.Done():
+		s.removePendingPing(acked)
+		return ctx.Err()
+	case <-s.ctx.Done():
+		s.removePendingPing(acked)
+		return context.Cause(s.ctx)
+	}
+}
+
+func (s *connection) removePendingPing(acked chan struct{}) {
+	s.pingMutex.Lock()
+	defer s.pingMutex.Unlock()
+	for i, c := range s.pendingPings {
+		if c == acked {
+			s.pendingPings = append(s.pendingPings[:i], s.pendingPings[i+1:]...)
+			return
+		}
+	}
+}
+
+// onPingAcked is called when a 1-RTT PING frame is acknowledged.
+// All PING frames are interchangeable, so this unblocks all pending calls to Ping.
+func (s *connection) onPingAcked() {
+	s.pingMutex.Lock()
+	for _, acked := range s.pendingPings {
+		close(acked)
+	}
+	s.pendingPings = nil
+	s.pingMutex.Unlock()
+}
+
 func (s *connection) LocalAddr() net.Addr {
 	return s.conn.LocalAddr()
 }
@@ -412,6 +412,54 @@ var _ = Describe("Connection", func() {
 		Expect(conn.GetVersion()).To(Equal(protocol.VersionNumber(4242)))
 	})
 
+	Context("pinging", func() {
+		It("sends a PING frame and returns when a PING is acknowledged", func() {
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- conn.Ping(context.Background())
+			}()
+			Eventually(conn.framer.HasData).Should(BeTrue())
+			frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.PingFrame{}}}))
+			Consistently(errChan).ShouldNot(Receive())
+			conn.retransmissionQueue.AppDataAckHandler().OnAcked(&wire.PingFrame{})
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("returns when the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- conn.Ping(ctx)
+			}()
+			Consistently(errChan).ShouldNot(Receive())
+			cancel()
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+		})
+
+		It("doesn't keep track of canceled calls", func() {
+			for i := 0; i < 10; i++ {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				Expect(conn.Ping(ctx)).To(MatchError(context.Canceled))
+			}
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- conn.Ping(context.Background())
+			}()
+			Eventually(func() int {
+				conn.pingMutex.Lock()
+				defer conn.pingMutex.Unlock()
+				return len(conn.pendingPings)
+			}).Should(Equal(1))
+			conn.retransmissionQueue.AppDataAckHandler().OnAcked(&wire.PingFrame{})
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+	})
+
 	Context("closing", func() {
 		var (
 			runErr         chan error
@@ -805,6 +853,76 @@ var _ = Describe("Connection", func() {
 			Expect(conn.handlePacketImpl(packet)).To(BeTrue())
 		})
 
+		It("reflects the spin bit", func() {
+			conn.spinBitEnabled = true
+			receive := func(pn protocol.PacketNumber, spin bool) {
+				b, err := (&wire.PingFrame{}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				packet := getShortHeaderPacket(srcConnID, pn, nil)
+				if spin {
+					packet.data[0] |= 0x20
+				}
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+			}
+			packer.EXPECT().SetSpinBit(true)
+			receive(10, true)
+			// reordered packets don't change the spin bit
+			receive(9, false)
+			packer.EXPECT().SetSpinBit(false)
+			receive(11, false)
+		})
+
+		It("keeps the spin bit fixed if disabled", func() {
+			conn.spinBitEnabled = false
+			for i, spin := range []bool{true, false, true, false} {
+				b, err := (&wire.PingFrame{}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				pn := protocol.PacketNumber(10 + i)
+				packet := getShortHeaderPacket(srcConnID, pn, nil)
+				if spin {
+					packet.data[0] |= 0x20
+				}
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				// the packer's SetSpinBit is never called, so all packets are sent with the spin bit unset
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+			}
+		})
+
+		It("disables the spin bit on one in 16 connections", func() {
+			conn.config.EnableSpinBit = true
+			conn.initSpinBit(func(n int) int {
+				Expect(n).To(Equal(16))
+				return 1
+			})
+			Expect(conn.spinBitEnabled).To(BeTrue())
+			// on connections that don't spin, the spin bit is set to a random value
+			for _, v := range []int{0, 1} {
+				var calls []int
+				packer.EXPECT().SetSpinBit(v == 1)
+				conn.initSpinBit(func(n int) int {
+					calls = append(calls, n)
+					if n == 16 {
+						return 0
+					}
+					return v
+				})
+				Expect(conn.spinBitEnabled).To(BeFalse())
+				Expect(calls).To(Equal([]int{16, 2}))
+			}
+		})
+
+		It("never enables the spin bit if not configured", func() {
+			conn.config.EnableSpinBit = false
+			for _, v := range []int{0, 1} {
+				packer.EXPECT().SetSpinBit(v == 1)
+				conn.initSpinBit(func(int) int { return v })
+				Expect(conn.spinBitEnabled).To(BeFalse())
+			}
+		})
+
 		It("drops duplicate packets", func() {
 			packet := getShortHeaderPacket(srcConnID, 0x37, nil)
 			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x1337), protocol.PacketNumberLen2, protocol.KeyPhaseOne, []byte("foobar"), nil)
@@ -2120,6 +2238,72 @@ var _ = Describe("Connection", func() {
 			conn.handleTransportParameters(params)
 			Expect(conn.earlyConnReady()).To(BeClosed())
 		})
+
+		It("exposes the peer's transport parameters", func() {
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:                 90 * time.Second,
+				InitialMaxStreamDataBidiLocal:  0x5000,
+				InitialMaxStreamDataBidiRemote: 0x6000,
+				InitialMaxStreamDataUni:        0x7000,
+				InitialMaxData:                 0x8000,
+				MaxBidiStreamNum:               10,
+				MaxUniStreamNum:                20,
+				MaxUDPPayloadSize:              1400,
+				MaxDatagramFrameSize:           protocol.InvalidByteCount,
+				ActiveConnectionIDLimit:        3,
+				InitialSourceConnectionID:      destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			cryptoSetup.EXPECT().ConnectionState().Return(handshake.ConnectionState{})
+			Expect(conn.ConnectionState().PeerTransportParameters).To(Equal(TransportParameters{
+				MaxIdleTimeout:                 90 * time.Second,
+				MaxUDPPayloadSize:              1400,
+				InitialMaxData:                 0x8000,
+				InitialMaxStreamDataBidiLocal:  0x5000,
+				InitialMaxStreamDataBidiRemote: 0x6000,
+				InitialMaxStreamDataUni:        0x7000,
+				MaxBidiStreams:                 10,
+				MaxUniStreams:                  20,
+			}))
+		})
+
+		It("reduces the configured packet size to the peer's max_udp_payload_size", func() {
+			conn.config.MaxUDPPayloadSize = 1400
+			conn.mtuDiscoverer = newMTUDiscoverer(conn.rttStats, 1400, nil)
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			params := &wire.TransportParameters{
+				MaxUDPPayloadSize:         1300,
+				ActiveConnectionIDLimit:   2,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			// the congestion controller uses the reduced size as well
+			sph.EXPECT().SetMaxDatagramSize(protocol.ByteCount(1300))
+			conn.handleTransportParameters(params)
+			Expect(conn.mtuDiscoverer.CurrentSize()).To(BeEquivalentTo(1300))
+		})
+
+		It("doesn't increase the configured packet size to the peer's max_udp_payload_size", func() {
+			conn.config.MaxUDPPayloadSize = 1400
+			conn.mtuDiscoverer = newMTUDiscoverer(conn.rttStats, 1400, nil)
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			params := &wire.TransportParameters{
+				MaxUDPPayloadSize:         protocol.MaxPacketBufferSize,
+				ActiveConnectionIDLimit:   2,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sph.EXPECT().SetMaxDatagramSize(protocol.ByteCount(1400))
+			conn.handleTransportParameters(params)
+			Expect(conn.mtuDiscoverer.CurrentSize()).To(BeEquivalentTo(1400))
+		})
 	})
 
 	Context("keep-alives", func() {
@@ -2570,6 +2754,46 @@ var _ = Describe("Client Connection", func() {
 		conn.sentFirstPacket = true
 	})
 
+	Context("with a configured initial RTT", func() {
+		BeforeEach(func() {
+			quicConf.InitialRTT = 2 * time.Second
+		})
+
+		It("uses the initial RTT", func() {
+			Expect(conn.rttStats.SmoothedRTT()).To(Equal(2 * time.Second))
+			Expect(conn.rttStats.PTO(false)).To(BeNumerically(">", 2*time.Second))
+		})
+	})
+
+	It("inverts the spin bit", func() {
+		conn.spinBitEnabled = true
+		conn.handshakeComplete = true
+		unpacker := NewMockUnpacker(mockCtrl)
+		conn.unpacker = unpacker
+		receive := func(pn protocol.PacketNumber, spin bool) {
+			b, err := (&wire.PingFrame{}).Append(nil, conn.version)
+			Expect(err).ToNot(HaveOccurred())
+			hdr, err := wire.AppendShortHeader(nil, srcConnID, pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero)
+			Expect(err).ToNot(HaveOccurred())
+			if spin {
+				hdr[0] |= 0x20
+			}
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+			tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(receivedPacket{
+				rcvTime: time.Now(),
+				data:    append(hdr, make([]byte, 20)...),
+				buffer:  getPacketBuffer(),
+			})).To(BeTrue())
+		}
+		packer.EXPECT().SetSpinBit(false)
+		receive(10, true)
+		// reordered packets don't change the spin bit
+		receive(9, false)
+		packer.EXPECT().SetSpinBit(true)
+		receive(11, false)
+	})
+
 	It("changes the connection ID when receiving the first packet from the server", func() {
 		unpacker := NewMockUnpacker(mockCtrl)
 		unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).DoAndReturn(func(hdr *wire.Header, _ time.Time, data []byte, _ protocol.VersionNumber) (*unpackedPacket, error) {
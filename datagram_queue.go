@@ -12,6 +12,9 @@ import (
 type datagramQueue struct {
 	sendQueue chan *wire.DatagramFrame
 	nextFrame *wire.DatagramFrame
+	// If set, AddAndWait blocks until the frame has been dequeued.
+	// Otherwise, frames are queued (up to the capacity of the sendQueue).
+	blocking bool
 
 	rcvMx    sync.Mutex
 	rcvQueue [][]byte
@@ -21,16 +24,25 @@ type datagramQueue struct {
 	closed   chan struct{}
 
 	hasData func()
+	dropped func([]byte) // called when a frame is dropped because it doesn't fit into a packet
 
 	dequeued chan struct{}
 
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+// newDatagramQueue creates a new datagramQueue.
+// If sendQueueLen is 0, AddAndWait blocks until the frame has been dequeued.
+func newDatagramQueue(hasData func(), dropped func([]byte), sendQueueLen int, logger utils.Logger) *datagramQueue {
+	blocking := sendQueueLen <= 0
+	if blocking {
+		sendQueueLen = 1
+	}
 	return &datagramQueue{
 		hasData:   hasData,
-		sendQueue: make(chan *wire.DatagramFrame, 1),
+		dropped:   dropped,
+		blocking:  blocking,
+		sendQueue: make(chan *wire.DatagramFrame, sendQueueLen),
 		rcvd:      make(chan struct{}, 1),
 		dequeued:  make(chan struct{}),
 		closed:    make(chan struct{}),
@@ -39,8 +51,24 @@ func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
 }
 
 // AddAndWait queues a new DATAGRAM frame for sending.
-// It blocks until the frame has been dequeued.
+// If the queue is blocking, it blocks until the frame has been dequeued.
+// Otherwise, it returns ErrDatagramQueueFull if the queue is full.
 func (h *datagramQueue) AddAndWait(f *wire.DatagramFrame) error {
+	if !h.blocking {
+		select {
+		case h.sendQueue <- f:
+			h.hasData()
+			return nil
+		case <-h.closed:
+			return h.closeErr
+		default:
+			if h.logger.Debug() {
+				h.logger.Debugf("Discarding DATAGRAM frame (%d bytes payload), send queue full", len(f.Data))
+			}
+			return ErrDatagramQueueFull
+		}
+	}
+
 	select {
 	case h.sendQueue <- f:
 		h.hasData()
@@ -64,7 +92,9 @@ func (h *datagramQueue) Peek() *wire.DatagramFrame {
 	}
 	select {
 	case h.nextFrame = <-h.sendQueue:
-		h.dequeued <- struct{}{}
+		if h.blocking {
+			h.dequeued <- struct{}{}
+		}
 	default:
 		return nil
 	}
@@ -78,6 +108,18 @@ func (h *datagramQueue) Pop() {
 	h.nextFrame = nil
 }
 
+// Drop drops the frame returned by Peek, because it doesn't fit into a packet.
+func (h *datagramQueue) Drop() {
+	f := h.nextFrame
+	h.Pop()
+	if h.logger.Debug() {
+		h.logger.Debugf("Discarding DATAGRAM frame (%d bytes payload), too large for the packet", len(f.Data))
+	}
+	if h.dropped != nil {
+		h.dropped(f.Data)
+	}
+}
+
 // HandleDatagramFrame handles a received DATAGRAM frame.
 func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame) {
 	data := make([]byte, len(f.Data))
@@ -17,7 +17,7 @@ var _ = Describe("Datagram Queue", func() {
 
 	BeforeEach(func() {
 		queued = make(chan struct{}, 100)
-		queue = newDatagramQueue(func() { queued <- struct{}{} }, utils.DefaultLogger)
+		queue = newDatagramQueue(func() { queued <- struct{}{} }, nil, 0, utils.DefaultLogger)
 	})
 
 	Context("sending", func() {
@@ -76,6 +76,33 @@ var _ = Describe("Datagram Queue", func() {
 			queue.CloseWithError(errors.New("test error"))
 			Eventually(errChan).Should(Receive(MatchError("test error")))
 		})
+
+		It("doesn't block when a send queue length is set", func() {
+			queue = newDatagramQueue(func() { queued <- struct{}{} }, nil, 2, utils.DefaultLogger)
+			Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queued).To(HaveLen(2))
+			Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("baz")})).To(MatchError(ErrDatagramQueueFull))
+			Expect(queued).To(HaveLen(2))
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			// dequeueing a frame frees up space in the queue
+			Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("baz")})).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
+
+		It("reports dropped frames", func() {
+			var dropped [][]byte
+			queue = newDatagramQueue(func() { queued <- struct{}{} }, func(b []byte) { dropped = append(dropped, b) }, 2, utils.DefaultLogger)
+			Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Drop()
+			Expect(dropped).To(Equal([][]byte{[]byte("foo")}))
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
 	})
 
 	Context("receiving", func() {
@@ -41,6 +41,23 @@ const (
 	NoViablePathError         = qerr.NoViablePathError
 )
 
+// A DatagramTooLargeError is returned from Connection.SendMessage
+// if the payload is too large to be sent in a single DATAGRAM frame.
+type DatagramTooLargeError struct {
+	// MaxDataLen is the maximum payload size of a DATAGRAM frame.
+	// It depends on the peer's max_datagram_frame_size transport parameter.
+	MaxDataLen int64
+}
+
+func (e *DatagramTooLargeError) Is(target error) bool {
+	_, ok := target.(*DatagramTooLargeError)
+	return ok
+}
+
+func (e *DatagramTooLargeError) Error() string {
+	return fmt.Sprintf("message too large (maximum %d bytes)", e.MaxDataLen)
+}
+
 // A StreamError is used for Stream.CancelRead and Stream.CancelWrite.
 // It is also returned from Stream.Read and Stream.Write if the peer canceled reading or writing.
 type StreamError struct {
@@ -27,6 +27,7 @@ func main() {
 			RootCAs:            testdata.GetRootCA(),
 			ClientSessionCache: tls.NewLRUClientSessionCache(1),
 		},
+		nil,
 		false,
 		utils.NewRTTStats(),
 		nil,
@@ -42,6 +43,7 @@ func main() {
 		&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
 		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
 		config,
+		nil,
 		false,
 		utils.NewRTTStats(),
 		nil,
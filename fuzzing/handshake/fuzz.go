@@ -280,6 +280,7 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		protocol.ConnectionID{},
 		clientTP,
 		clientConf,
+		nil,
 		enable0RTTClient,
 		utils.NewRTTStats(),
 		nil,
@@ -297,6 +298,7 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
 		serverTP,
 		serverConf,
+		nil,
 		enable0RTTServer,
 		utils.NewRTTStats(),
 		nil,
@@ -182,6 +182,12 @@ type Server struct {
 	// used.
 	MaxHeaderBytes int
 
+	// ReadHeaderTimeout is the amount of time allowed to read the request HEADERS frame.
+	// The timer starts when the request stream is accepted.
+	// If zero, there is no timeout.
+	// Use QuicConfig.MaxIncomingStreams to limit the number of concurrent requests per connection.
+	ReadHeaderTimeout time.Duration
+
 	// AdditionalSettings specifies additional HTTP/3 settings.
 	// It is invalid to specify any settings defined by the HTTP/3 draft and the datagram draft.
 	AdditionalSettings map[uint64]uint64
@@ -541,9 +547,17 @@ func (s *Server) maxHeaderBytes() uint64 {
 }
 
 func (s *Server) handleRequest(conn quic.Connection, str quic.Stream, decoder *qpack.Decoder, onFrameError func()) requestError {
+	if s.ReadHeaderTimeout > 0 {
+		str.SetReadDeadline(time.Now().Add(s.ReadHeaderTimeout))
+	}
 	var ufh unknownFrameHandlerFunc
 	if s.StreamHijacker != nil {
-		ufh = func(ft FrameType, e error) (processed bool, err error) { return s.StreamHijacker(ft, conn, str, e) }
+		ufh = func(ft FrameType, e error) (processed bool, err error) {
+			if s.ReadHeaderTimeout > 0 {
+				str.SetReadDeadline(time.Time{})
+			}
+			return s.StreamHijacker(ft, conn, str, e)
+		}
 	}
 	frame, err := parseNextFrame(str, ufh)
 	if err != nil {
@@ -563,6 +577,9 @@ func (s *Server) handleRequest(conn quic.Connection, str quic.Stream, decoder *q
 	if _, err := io.ReadFull(str, headerBlock); err != nil {
 		return newStreamError(ErrCodeRequestIncomplete, err)
 	}
+	if s.ReadHeaderTimeout > 0 {
+		str.SetReadDeadline(time.Time{})
+	}
 	hfs, err := decoder.DecodeFull(headerBlock)
 	if err != nil {
 		// TODO: use the right error code
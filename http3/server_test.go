@@ -710,6 +710,48 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("sets a read deadline while reading the HEADERS frame", func() {
+				s.ReadHeaderTimeout = time.Minute
+				handlerCalled := make(chan struct{})
+				s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					close(handlerCalled)
+				})
+
+				setRequest(encodeRequest(exampleGetRequest))
+				gomock.InOrder(
+					str.EXPECT().SetReadDeadline(gomock.Any()).Do(func(t time.Time) {
+						Expect(t).To(BeTemporally("~", time.Now().Add(time.Minute), scaleDuration(10*time.Millisecond)))
+					}),
+					str.EXPECT().SetReadDeadline(time.Time{}),
+				)
+				str.EXPECT().Context().Return(reqContext)
+				str.EXPECT().Write(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+					return len(p), nil
+				}).AnyTimes()
+				str.EXPECT().CancelRead(gomock.Any())
+				done := make(chan struct{})
+				str.EXPECT().Close().Do(func() { close(done) })
+
+				s.handleConn(conn)
+				Eventually(handlerCalled).Should(BeClosed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("resets the stream when reading the HEADERS frame times out", func() {
+				s.ReadHeaderTimeout = 10 * time.Millisecond
+				s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					Fail("Handler should not be called.")
+				})
+
+				str.EXPECT().SetReadDeadline(gomock.Any())
+				str.EXPECT().Read(gomock.Any()).Return(0, &net.OpError{Op: "read", Err: errors.New("deadline exceeded")})
+				done := make(chan struct{})
+				str.EXPECT().CancelWrite(quic.StreamErrorCode(ErrCodeRequestIncomplete)).Do(func(quic.StreamErrorCode) { close(done) })
+
+				s.handleConn(conn)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("handles a request for which the client immediately resets the stream", func() {
 				handlerCalled := make(chan struct{})
 				s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -3,8 +3,12 @@ package self_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/quic-go/quic-go"
 )
@@ -90,3 +94,150 @@ func BenchmarkStreamChurn(b *testing.B) {
 		}
 	}
 }
+
+func benchmarkTransfer(b *testing.B, numStreams int) {
+	const size = 1 << 20 // 1 MB per stream
+
+	b.ReportAllocs()
+	b.SetBytes(size * int64(numStreams))
+
+	ln, err := quic.ListenAddr("localhost:0", tlsConfig, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer ln.Close()
+
+	// one for Accept, and one for every stream, so that no receiving goroutine blocks forever
+	errChan := make(chan error, numStreams+1)
+	received := make(chan time.Time, numStreams) // the time a stream was completely received
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			errChan <- err
+			return
+		}
+		for {
+			str, err := conn.AcceptUniStream(context.Background())
+			if err != nil {
+				return
+			}
+			go func() {
+				if _, err := io.Copy(io.Discard, str); err != nil {
+					errChan <- err
+					return
+				}
+				received <- time.Now()
+			}()
+		}
+	}()
+
+	c, err := quic.DialAddr(context.Background(), fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port), tlsClientConfig, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.CloseWithError(0, "")
+	data := make([]byte, size)
+
+	// The spread between the first and the last stream completing shows how fairly
+	// the bandwidth is shared between streams.
+	var spread time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(numStreams)
+		for j := 0; j < numStreams; j++ {
+			go func() {
+				defer wg.Done()
+				str, err := c.OpenUniStreamSync(context.Background())
+				if err != nil {
+					b.Error(err)
+					return
+				}
+				if _, err := str.Write(data); err != nil {
+					b.Error(err)
+					return
+				}
+				if err := str.Close(); err != nil {
+					b.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		// wait until the server has received all the data
+		var first, last time.Time
+		for j := 0; j < numStreams; j++ {
+			select {
+			case t := <-received:
+				if first.IsZero() || t.Before(first) {
+					first = t
+				}
+				if t.After(last) {
+					last = t
+				}
+			case err := <-errChan:
+				b.Fatal(err)
+			}
+		}
+		spread += last.Sub(first)
+	}
+	if numStreams > 1 {
+		b.ReportMetric(float64(spread.Microseconds())/float64(b.N), "us-spread/op")
+	}
+}
+
+func BenchmarkTransferSingleStream(b *testing.B)    { benchmarkTransfer(b, 1) }
+func BenchmarkTransferMultipleStreams(b *testing.B) { benchmarkTransfer(b, 10) }
+func BenchmarkTransferManyStreams(b *testing.B)     { benchmarkTransfer(b, 100) }
+
+// BenchmarkMemoryPerConnection measures the heap memory used by an idle connection,
+// including the memory used by both the client and the server side.
+func BenchmarkMemoryPerConnection(b *testing.B) {
+	const numConns = 100
+
+	ln, err := quic.ListenAddr("localhost:0", tlsConfig, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer ln.Close()
+
+	connChan := make(chan quic.Connection, numConns)
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			connChan <- conn
+		}
+	}()
+
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tr := &quic.Transport{Conn: conn}
+	defer tr.Close()
+
+	var total uint64
+	var before, after runtime.MemStats
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		conns := make([]quic.Connection, 0, 2*numConns)
+		for j := 0; j < numConns; j++ {
+			c, err := tr.Dial(context.Background(), ln.Addr(), tlsClientConfig, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			conns = append(conns, c, <-connChan)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		total += after.HeapAlloc - before.HeapAlloc
+		for _, c := range conns {
+			c.CloseWithError(0, "")
+		}
+	}
+	b.ReportMetric(float64(total)/float64(b.N*numConns), "B/conn")
+}
@@ -0,0 +1,71 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duplication Tests", func() {
+	for _, d := range directions {
+		direction := d
+
+		It(fmt.Sprintf("downloads a message, duplicating and reordering packets in %s direction", direction), func() {
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+			go func() {
+				defer GinkgoRecover()
+				conn, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				str, err := conn.OpenStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write(PRData)
+				Expect(err).ToNot(HaveOccurred())
+				str.Close()
+			}()
+
+			var numPackets int32
+			serverPort := ln.Addr().(*net.UDPAddr).Port
+			proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+				RemoteAddr: fmt.Sprintf("localhost:%d", serverPort),
+				DuplicatePacket: func(d quicproxy.Direction, _ []byte) bool {
+					if !d.Is(direction) {
+						return false
+					}
+					// duplicate every 3rd packet
+					return atomic.AddInt32(&numPackets, 1)%3 == 0
+				},
+				DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+					return randomDuration(5*time.Millisecond, 15*time.Millisecond)
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			defer proxy.Close()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+			Expect(atomic.LoadInt32(&numPackets)).To(BeNumerically(">=", 3))
+		})
+	}
+})
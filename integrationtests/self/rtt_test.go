@@ -110,4 +110,37 @@ var _ = Describe("non-zero RTT", func() {
 			downloadFile(proxy.LocalPort())
 		})
 	}
+
+	It("pings the peer", func() {
+		const rtt = 100 * time.Millisecond
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		serverAddr := ln.Addr().(*net.UDPAddr)
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", serverAddr.Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer serverConn.CloseWithError(0, "")
+
+		start := time.Now()
+		Expect(conn.Ping(context.Background())).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", rtt))
+		start = time.Now()
+		Expect(serverConn.Ping(context.Background())).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", rtt))
+	})
 })
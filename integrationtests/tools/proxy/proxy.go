@@ -19,6 +19,11 @@ type connection struct {
 
 	Incoming *queue
 	Outgoing *queue
+
+	// The time until which the link is busy sending previous packets, if a bandwidth cap is set.
+	// Only accessed from the goroutine reading packets in the respective direction.
+	incomingLinkFree time.Time
+	outgoingLinkFree time.Time
 }
 
 func (c *connection) queuePacket(t time.Time, b []byte) {
@@ -126,6 +131,14 @@ var NoDelay DelayCallback = func(Direction, []byte) time.Duration {
 	return 0
 }
 
+// DuplicateCallback is a callback that determines which packet gets duplicated.
+type DuplicateCallback func(dir Direction, packet []byte) bool
+
+// NoDuplicator doesn't duplicate packets.
+var NoDuplicator DuplicateCallback = func(Direction, []byte) bool {
+	return false
+}
+
 // Opts are proxy options.
 type Opts struct {
 	// The address this proxy proxies packets to.
@@ -136,9 +149,17 @@ type Opts struct {
 	// simulating a connection with non-zero RTTs.
 	// Note that the RTT is the sum of the delay for the incoming and the outgoing packet.
 	DelayPacket DelayCallback
+	// DuplicatePacket determines whether a packet gets duplicated.
+	// DelayPacket is evaluated separately for the original packet and the duplicate.
+	DuplicatePacket DuplicateCallback
+	// Bandwidth is the bandwidth of the link in each direction, in bytes per second.
+	// Packets are queued until all previous packets have been sent, the delay from DelayPacket
+	// is applied afterwards. Queued packets are never dropped.
+	// If zero, the bandwidth is not limited.
+	Bandwidth int64
 }
 
-// QuicProxy is a QUIC proxy that can drop and delay packets.
+// QuicProxy is a QUIC proxy that can drop, delay and duplicate packets, and limit the bandwidth.
 type QuicProxy struct {
 	mutex sync.Mutex
 
@@ -147,8 +168,10 @@ type QuicProxy struct {
 	conn       *net.UDPConn
 	serverAddr *net.UDPAddr
 
-	dropPacket  DropCallback
-	delayPacket DelayCallback
+	dropPacket      DropCallback
+	delayPacket     DelayCallback
+	duplicatePacket DuplicateCallback
+	bandwidth       int64
 
 	// Mapping from client addresses (as host:port) to connection
 	clientDict map[string]*connection
@@ -190,14 +213,21 @@ func NewQuicProxy(local string, opts *Opts) (*QuicProxy, error) {
 		packetDelayer = opts.DelayPacket
 	}
 
+	packetDuplicator := NoDuplicator
+	if opts.DuplicatePacket != nil {
+		packetDuplicator = opts.DuplicatePacket
+	}
+
 	p := QuicProxy{
-		clientDict:  make(map[string]*connection),
-		conn:        conn,
-		closeChan:   make(chan struct{}),
-		serverAddr:  raddr,
-		dropPacket:  packetDropper,
-		delayPacket: packetDelayer,
-		logger:      utils.DefaultLogger.WithPrefix("proxy"),
+		clientDict:      make(map[string]*connection),
+		conn:            conn,
+		closeChan:       make(chan struct{}),
+		serverAddr:      raddr,
+		dropPacket:      packetDropper,
+		delayPacket:     packetDelayer,
+		duplicatePacket: packetDuplicator,
+		bandwidth:       opts.Bandwidth,
+		logger:          utils.DefaultLogger.WithPrefix("proxy"),
 	}
 
 	p.logger.Debugf("Starting UDP Proxy %s <-> %s", conn.LocalAddr(), raddr)
@@ -283,24 +313,48 @@ func (p *QuicProxy) runProxy() error {
 			continue
 		}
 
-		delay := p.delayPacket(DirectionIncoming, raw)
-		if delay == 0 {
+		copies := 1
+		if p.duplicatePacket(DirectionIncoming, raw) {
 			if p.logger.Debug() {
-				p.logger.Debugf("forwarding incoming packet (%d bytes) to %s", len(raw), conn.ServerConn.RemoteAddr())
+				p.logger.Debugf("duplicating incoming packet (%d bytes)", n)
 			}
-			if _, err := conn.ServerConn.Write(raw); err != nil {
-				return err
-			}
-		} else {
-			now := time.Now()
-			if p.logger.Debug() {
-				p.logger.Debugf("delaying incoming packet (%d bytes) to %s by %s", len(raw), conn.ServerConn.RemoteAddr(), delay)
+			copies = 2
+		}
+		for i := 0; i < copies; i++ {
+			delay := p.delayPacket(DirectionIncoming, raw)
+			if delay == 0 && p.bandwidth == 0 {
+				if p.logger.Debug() {
+					p.logger.Debugf("forwarding incoming packet (%d bytes) to %s", len(raw), conn.ServerConn.RemoteAddr())
+				}
+				if _, err := conn.ServerConn.Write(raw); err != nil {
+					return err
+				}
+			} else {
+				sendTime := p.linkSendTime(&conn.incomingLinkFree, time.Now(), len(raw)).Add(delay)
+				if p.logger.Debug() {
+					p.logger.Debugf("delaying incoming packet (%d bytes) to %s by %s", len(raw), conn.ServerConn.RemoteAddr(), time.Until(sendTime))
+				}
+				conn.queuePacket(sendTime, raw)
 			}
-			conn.queuePacket(now.Add(delay), raw)
 		}
 	}
 }
 
+// linkSendTime returns the time when a packet has been completely sent on a link with the configured bandwidth.
+// The link is busy until all previously queued packets have been sent.
+func (p *QuicProxy) linkSendTime(linkFree *time.Time, now time.Time, size int) time.Time {
+	if p.bandwidth == 0 {
+		return now
+	}
+	t := now
+	if linkFree.After(t) {
+		t = *linkFree
+	}
+	t = t.Add(time.Duration(int64(size) * int64(time.Second) / p.bandwidth))
+	*linkFree = t
+	return t
+}
+
 // runConnection handles packets from server to a single client
 func (p *QuicProxy) runOutgoingConnection(conn *connection) error {
 	outgoingPackets := make(chan packetEntry, 10)
@@ -320,20 +374,29 @@ func (p *QuicProxy) runOutgoingConnection(conn *connection) error {
 				continue
 			}
 
-			delay := p.delayPacket(DirectionOutgoing, raw)
-			if delay == 0 {
+			copies := 1
+			if p.duplicatePacket(DirectionOutgoing, raw) {
 				if p.logger.Debug() {
-					p.logger.Debugf("forwarding outgoing packet (%d bytes) to %s", len(raw), conn.ClientAddr)
-				}
-				if _, err := p.conn.WriteToUDP(raw, conn.ClientAddr); err != nil {
-					return
+					p.logger.Debugf("duplicating outgoing packet (%d bytes)", n)
 				}
-			} else {
-				now := time.Now()
-				if p.logger.Debug() {
-					p.logger.Debugf("delaying outgoing packet (%d bytes) to %s by %s", len(raw), conn.ClientAddr, delay)
+				copies = 2
+			}
+			for i := 0; i < copies; i++ {
+				delay := p.delayPacket(DirectionOutgoing, raw)
+				if delay == 0 && p.bandwidth == 0 {
+					if p.logger.Debug() {
+						p.logger.Debugf("forwarding outgoing packet (%d bytes) to %s", len(raw), conn.ClientAddr)
+					}
+					if _, err := p.conn.WriteToUDP(raw, conn.ClientAddr); err != nil {
+						return
+					}
+				} else {
+					sendTime := p.linkSendTime(&conn.outgoingLinkFree, time.Now(), len(raw)).Add(delay)
+					if p.logger.Debug() {
+						p.logger.Debugf("delaying outgoing packet (%d bytes) to %s by %s", len(raw), conn.ClientAddr, time.Until(sendTime))
+					}
+					outgoingPackets <- packetEntry{Time: sendTime, Raw: raw}
 				}
-				outgoingPackets <- packetEntry{Time: now.Add(delay), Raw: raw}
 			}
 		}
 	}()
@@ -304,6 +304,62 @@ var _ = Describe("QUIC Proxy", func() {
 			})
 		})
 
+		Context("Duplicate Callback", func() {
+			It("duplicates incoming packets", func() {
+				var counter int32
+				opts := &Opts{
+					RemoteAddr: serverConn.LocalAddr().String(),
+					DuplicatePacket: func(d Direction, _ []byte) bool {
+						if d != DirectionIncoming {
+							return false
+						}
+						return atomic.AddInt32(&counter, 1)%2 == 1
+					},
+				}
+				startProxy(opts)
+
+				for i := 1; i <= 4; i++ {
+					_, err := clientConn.Write(makePacket(protocol.PacketNumber(i), []byte("foobar"+strconv.Itoa(i))))
+					Expect(err).ToNot(HaveOccurred())
+				}
+				Eventually(serverReceivedPackets).Should(HaveLen(6))
+				Consistently(serverReceivedPackets).Should(HaveLen(6))
+			})
+
+			It("duplicates outgoing packets", func() {
+				const numPackets = 4
+				opts := &Opts{
+					RemoteAddr:      serverConn.LocalAddr().String(),
+					DuplicatePacket: func(d Direction, _ []byte) bool { return d == DirectionOutgoing },
+				}
+				startProxy(opts)
+
+				clientReceivedPackets := make(chan packetData, 2*numPackets)
+				// receive the packets echoed by the server on client side
+				go func() {
+					for {
+						buf := make([]byte, protocol.MaxPacketBufferSize)
+						// the ReadFromUDP will error as soon as the UDP conn is closed
+						n, _, err2 := clientConn.ReadFromUDP(buf)
+						if err2 != nil {
+							return
+						}
+						data := buf[0:n]
+						clientReceivedPackets <- packetData(data)
+					}
+				}()
+
+				for i := 1; i <= numPackets; i++ {
+					_, err := clientConn.Write(makePacket(protocol.PacketNumber(i), []byte("foobar"+strconv.Itoa(i))))
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				Eventually(serverReceivedPackets).Should(HaveLen(numPackets))
+				Eventually(clientReceivedPackets).Should(HaveLen(2 * numPackets))
+				Consistently(clientReceivedPackets).Should(HaveLen(2 * numPackets))
+			})
+		})
+
 		Context("Delay Callback", func() {
 			const delay = 200 * time.Millisecond
 			expectDelay := func(startTime time.Time, numRTTs int) {
@@ -457,5 +513,94 @@ var _ = Describe("QUIC Proxy", func() {
 				Expect(readPacketNumber(<-clientReceivedPackets)).To(Equal(protocol.PacketNumber(3)))
 			})
 		})
+
+		Context("Bandwidth", func() {
+			const packetSize = 500
+			const bandwidth = 10 * packetSize // 10 packets per second, i.e. 100ms per packet
+			const packetTime = 100 * time.Millisecond
+
+			expectTime := func(startTime time.Time, numPackets int) {
+				expectedReceiveTime := startTime.Add(time.Duration(numPackets) * packetTime)
+				Expect(time.Now()).To(SatisfyAll(
+					BeTemporally(">=", expectedReceiveTime),
+					BeTemporally("<", expectedReceiveTime.Add(packetTime/2)),
+				))
+			}
+			newPacket := func(pn protocol.PacketNumber) []byte {
+				b := makePacket(pn, make([]byte, packetSize-len(makePacket(pn, nil))))
+				Expect(b).To(HaveLen(packetSize))
+				return b
+			}
+
+			It("limits the bandwidth of incoming packets", func() {
+				startProxy(&Opts{
+					RemoteAddr: serverConn.LocalAddr().String(),
+					Bandwidth:  bandwidth,
+				})
+				start := time.Now()
+				for i := 1; i <= 3; i++ {
+					_, err := clientConn.Write(newPacket(protocol.PacketNumber(i)))
+					Expect(err).ToNot(HaveOccurred())
+				}
+				for i := 1; i <= 3; i++ {
+					Eventually(serverReceivedPackets).Should(HaveLen(1))
+					expectTime(start, i)
+					Expect(readPacketNumber(<-serverReceivedPackets)).To(Equal(protocol.PacketNumber(i)))
+				}
+			})
+
+			It("applies the delay after the bandwidth limit", func() {
+				startProxy(&Opts{
+					RemoteAddr: serverConn.LocalAddr().String(),
+					Bandwidth:  bandwidth,
+					DelayPacket: func(d Direction, _ []byte) time.Duration {
+						if d == DirectionIncoming {
+							return packetTime
+						}
+						return 0
+					},
+				})
+				start := time.Now()
+				for i := 1; i <= 2; i++ {
+					_, err := clientConn.Write(newPacket(protocol.PacketNumber(i)))
+					Expect(err).ToNot(HaveOccurred())
+				}
+				for i := 1; i <= 2; i++ {
+					Eventually(serverReceivedPackets).Should(HaveLen(1))
+					expectTime(start, i+1)
+					Expect(readPacketNumber(<-serverReceivedPackets)).To(Equal(protocol.PacketNumber(i)))
+				}
+			})
+
+			It("limits the bandwidth of outgoing packets", func() {
+				startProxy(&Opts{
+					RemoteAddr: serverConn.LocalAddr().String(),
+					Bandwidth:  bandwidth,
+				})
+				clientReceivedPackets := make(chan packetData, 3)
+				go func() {
+					for {
+						buf := make([]byte, protocol.MaxPacketBufferSize)
+						n, _, err := clientConn.ReadFromUDP(buf)
+						if err != nil {
+							return
+						}
+						clientReceivedPackets <- packetData(buf[:n])
+					}
+				}()
+				start := time.Now()
+				for i := 1; i <= 2; i++ {
+					_, err := clientConn.Write(newPacket(protocol.PacketNumber(i)))
+					Expect(err).ToNot(HaveOccurred())
+				}
+				// the server echoes every packet as soon as it receives it,
+				// so the second packet is only sent after the first one was echoed
+				for i := 1; i <= 2; i++ {
+					Eventually(clientReceivedPackets).Should(HaveLen(1))
+					expectTime(start, i+1)
+					Expect(readPacketNumber(<-clientReceivedPackets)).To(Equal(protocol.PacketNumber(i)))
+				}
+			})
+		})
 	})
 })
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"syscall"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/handshake"
@@ -50,6 +51,10 @@ type TokenStore interface {
 // when the server rejects a 0-RTT connection attempt.
 var Err0RTTRejected = errors.New("0-RTT rejected")
 
+// ErrDatagramQueueFull is returned from Connection.SendMessage if the datagram send queue is full.
+// This only happens if Config.DatagramSendQueueLen is set.
+var ErrDatagramQueueFull = errors.New("datagram send queue full")
+
 // ConnectionTracingKey can be used to associate a ConnectionTracer with a Connection.
 // It is set on the Connection.Context() context,
 // as well as on the context passed to logging.Tracer.NewConnectionTracer.
@@ -188,9 +193,20 @@ type Connection interface {
 	ConnectionState() ConnectionState
 
 	// SendMessage sends a message as a datagram, as specified in RFC 9221.
+	// If the message is too large to be sent in a single datagram, a *DatagramTooLargeError is returned.
+	// By default, SendMessage blocks until the datagram was dequeued for sending.
+	// If Config.DatagramSendQueueLen is set, it returns immediately,
+	// and returns ErrDatagramQueueFull if the datagram can't be queued.
+	// Queued datagrams that don't fit into a packet are dropped, and reported using Config.DatagramDropped.
 	SendMessage([]byte) error
 	// ReceiveMessage gets a message received in a datagram, as specified in RFC 9221.
 	ReceiveMessage(context.Context) ([]byte, error)
+	// Ping sends a PING frame, and blocks until the peer acknowledges a PING frame.
+	// It can be used to check that the peer is still reachable, e.g. before reusing an idle connection.
+	// It returns an error if the context is canceled, or if the connection is closed.
+	// Since all PING frames are interchangeable, the acknowledgement of a PING frame that was already
+	// in flight (e.g. a keep-alive or a PTO probe) might unblock the call before its own PING is acknowledged.
+	Ping(context.Context) error
 }
 
 // An EarlyConnection is a connection that is handshaking.
@@ -252,6 +268,14 @@ type Config struct {
 	// The QUIC versions that can be negotiated.
 	// If not set, it uses all versions available.
 	Versions []VersionNumber
+	// AcceptVersion determines if the server accepts a connection from remoteAddr using the QUIC version v.
+	// It is only called for versions contained in Versions, and is only valid for the server.
+	// If false is returned, the server sends a Version Negotiation packet, offering only the versions
+	// that AcceptVersion returns true for.
+	// This allows rolling out new versions gradually, or on a per-client basis.
+	// If not set, all versions contained in Versions are accepted.
+	// It may be called concurrently from multiple goroutines, so it must be safe for concurrent use.
+	AcceptVersion func(remoteAddr net.Addr, v VersionNumber) bool
 	// HandshakeIdleTimeout is the idle timeout before completion of the handshake.
 	// If we don't receive any packet from the peer within this time, the connection attempt is aborted.
 	// Additionally, if the handshake doesn't complete in twice this time, the connection attempt is also aborted.
@@ -263,16 +287,65 @@ type Config struct {
 	// If the timeout is exceeded, the connection is closed.
 	// If this value is zero, the timeout is set to 30 seconds.
 	MaxIdleTimeout time.Duration
+	// InitialRTT is the RTT estimate used before the first RTT sample is taken.
+	// It determines the retransmission timeouts during the handshake.
+	// Increasing this value avoids spurious retransmissions of handshake packets on long-delay paths.
+	// If this value is zero, the estimate is 100ms.
+	InitialRTT time.Duration
+	// MaxAckDelay is the maximum time by which the sending of acknowledgements is delayed.
+	// This value is advertised to the peer in the max_ack_delay transport parameter.
+	// If this value is zero, it is set to 25ms. Values larger than 2^14-2 ms are reduced to that value.
+	// Since max_ack_delay is encoded in milliseconds, fractional values are rounded up to the next millisecond.
+	MaxAckDelay time.Duration
+	// PacketsBeforeAck is the number of ack-eliciting packets received before an acknowledgement is sent
+	// (without waiting for the MaxAckDelay).
+	// Lower values reduce the latency of loss recovery at the cost of sending more acknowledgements,
+	// a value of 1 acknowledges every ack-eliciting packet immediately.
+	// If this value is zero, it is set to 2.
+	PacketsBeforeAck int
 	// RequireAddressValidation determines if a QUIC Retry packet is sent.
 	// This allows the server to verify the client's address, at the cost of increasing the handshake latency by 1 RTT.
 	// See https://datatracker.ietf.org/doc/html/rfc9000#section-8 for details.
 	// If not set, every client is forced to prove its remote address.
 	RequireAddressValidation func(net.Addr) bool
+	// AcceptClient is called for every packet that would create a new connection on the server,
+	// before any cryptographic processing is done.
+	// If it returns false, the packet is dropped without sending a response.
+	// This can be used to cheaply reject blocked addresses, or to enforce an allowlist.
+	// If not set, packets from all addresses are accepted.
+	// Only valid for the server.
+	AcceptClient func(net.Addr) bool
+	// MaxHandshakesPerIP is the maximum number of concurrent handshakes with a single client.
+	// Clients are identified by their IP address, IPv6 addresses are grouped by their /64 prefix.
+	// Packets that would start a handshake exceeding this limit are dropped,
+	// unless SendRetryOnHandshakeLimit is set.
+	// If zero, the number of handshakes isn't limited.
+	// Only valid for the server.
+	MaxHandshakesPerIP int
+	// MaxConnectionsPerIP is the maximum number of concurrent connections with a single client,
+	// including connections that are still handshaking.
+	// Clients are identified in the same way as for MaxHandshakesPerIP.
+	// Packets that would start a connection exceeding this limit are dropped.
+	// If zero, the number of connections isn't limited.
+	// Only valid for the server.
+	MaxConnectionsPerIP int
+	// SendRetryOnHandshakeLimit determines what happens when a client exceeds MaxHandshakesPerIP.
+	// If set, a Retry is sent to clients that haven't proven ownership of their address yet,
+	// and handshakes with clients that have are not subject to MaxHandshakesPerIP.
+	// This prevents attackers from using up the limit of a client by spoofing its address.
+	// Only valid for the server.
+	SendRetryOnHandshakeLimit bool
 	// The TokenStore stores tokens received from the server.
 	// Tokens are used to skip address validation on future connection attempts.
 	// The key used to store tokens is the ServerName from the tls.Config, if set
 	// otherwise the token is associated with the server's IP address.
 	TokenStore TokenStore
+	// The Resolver is used by DialAddr and DialAddrEarly to resolve the host name.
+	// This allows using a custom DNS server, or a different resolution mechanism altogether,
+	// by setting the resolver's Dial function.
+	// If not set, net.DefaultResolver is used.
+	// Only valid for the client.
+	Resolver *net.Resolver
 	// InitialStreamReceiveWindow is the initial size of the stream-level flow control window for receiving data.
 	// If the application is consuming data quickly enough, the flow control auto-tuning algorithm
 	// will increase the window up to MaxStreamReceiveWindow.
@@ -319,11 +392,60 @@ type Config struct {
 	// Path MTU discovery is only available on systems that allow setting of the Don't Fragment (DF) bit.
 	// If unavailable or disabled, packets will be at most 1252 (IPv4) / 1232 (IPv6) bytes in size.
 	DisablePathMTUDiscovery bool
+	// MaxUDPPayloadSize is the maximum size of the UDP payload of QUIC packets.
+	// By default, packets are sent at a conservative size (1252 bytes for IPv4, 1232 bytes for IPv6),
+	// which is increased by Path MTU Discovery.
+	// If set, packets are sent using (and never exceed) this size right from the start of the connection,
+	// and this value is advertised to the peer in the max_udp_payload_size transport parameter.
+	// This is only useful for networks with a known MTU. Packets that exceed the MTU of the path are dropped.
+	// Values must be at least 1200, and values larger than 1452 bytes are reduced to 1452 bytes.
+	MaxUDPPayloadSize uint16
+	// EnableSpinBit enables the latency spin bit (see section 17.4 of RFC 9000).
+	// The spin bit allows on-path observers to passively measure the RTT of the connection.
+	// As required by RFC 9000, the spin bit is still disabled for a random selection of connections.
+	// Connections that don't use the spin bit set it to a random value.
+	EnableSpinBit bool
+	// DSCP is the Differentiated Services Code Point (see RFC 2474) that outgoing packets are marked with.
+	// This allows prioritizing traffic on networks that implement QoS,
+	// e.g. using EF (46) for real-time media, or one of the AF classes for bulk transfers.
+	// It is only supported on platforms where quic-go can set control messages on outgoing packets
+	// (Linux, macOS and FreeBSD), and ignored otherwise.
+	// Valid values are 0 to 63.
+	DSCP uint8
+	// Control is called for the UDP sockets created by ListenAddr, ListenAddrEarly, DialAddr and DialAddrEarly,
+	// after creating the socket, but before binding it.
+	// This allows setting socket options, e.g. SO_BINDTODEVICE, SO_MARK or the socket buffer sizes.
+	// See net.ListenConfig.Control for details.
+	// It is not called for connections passed to Transport, Listen or Dial.
+	Control func(network, address string, c syscall.RawConn) error
 	// Allow0RTT allows the application to decide if a 0-RTT connection attempt should be accepted.
 	// Only valid for the server.
 	Allow0RTT bool
+	// AllowedCipherSuites restricts the TLS 1.3 cipher suites that can be used on a connection.
+	// crypto/tls doesn't allow configuring the TLS 1.3 cipher suites (tls.Config.CipherSuites only applies to TLS 1.2),
+	// so this is checked after the cipher suite was negotiated:
+	// If the negotiated suite is not contained in this list, the handshake fails with a handshake_failure alert,
+	// even if both endpoints would have supported another cipher suite from this list.
+	// If empty, all TLS 1.3 cipher suites are allowed.
+	AllowedCipherSuites []uint16
 	// Enable QUIC datagram support (RFC 9221).
 	EnableDatagrams bool
+	// MaxDatagramFrameSize is the maximum size of DATAGRAM frames that the peer is allowed to send.
+	// If zero, it defaults to 1200 bytes.
+	// Values larger than 1452 bytes are reduced to 1452 bytes.
+	MaxDatagramFrameSize uint16
+	// DatagramSendQueueLen is the number of datagrams that can be queued for sending.
+	// If set, SendMessage doesn't block, but datagrams are dropped (and ErrDatagramQueueFull is returned)
+	// when the queue is full.
+	// This allows applications sending real-time data to adapt their send rate.
+	// If zero, SendMessage blocks until the datagram was dequeued for sending.
+	DatagramSendQueueLen int
+	// DatagramDropped is called when a datagram that was queued using SendMessage is dropped,
+	// because it doesn't fit into a packet.
+	// This can happen when the datagram is smaller than the peer's limit,
+	// but larger than the space available in a packet.
+	// It is called from the connection's run loop, and should return quickly.
+	DatagramDropped func(conn Connection, data []byte)
 	Tracer          func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
 }
 
@@ -346,4 +468,32 @@ type ConnectionState struct {
 	Version VersionNumber
 	// GSO says if generic segmentation offload is used
 	GSO bool
+	// PeerTransportParameters are the transport parameters sent by the peer.
+	// They are only available once the peer's transport parameters have been received.
+	// For 0-RTT connections on the client side, they are the transport parameters
+	// restored from the session ticket until the handshake completes.
+	PeerTransportParameters TransportParameters
+}
+
+// TransportParameters are the transport parameters of a QUIC connection (RFC 9000, section 18.2).
+type TransportParameters struct {
+	// MaxIdleTimeout is the idle timeout. A value of 0 means that no idle timeout was set.
+	MaxIdleTimeout time.Duration
+	// MaxUDPPayloadSize is the maximum UDP payload size that the endpoint is willing to receive.
+	MaxUDPPayloadSize int64
+	// InitialMaxData is the initial connection-level flow control limit.
+	InitialMaxData int64
+	// InitialMaxStreamDataBidiLocal is the initial flow control limit for locally-initiated bidirectional streams.
+	InitialMaxStreamDataBidiLocal int64
+	// InitialMaxStreamDataBidiRemote is the initial flow control limit for peer-initiated bidirectional streams.
+	InitialMaxStreamDataBidiRemote int64
+	// InitialMaxStreamDataUni is the initial flow control limit for unidirectional streams.
+	InitialMaxStreamDataUni int64
+	// MaxBidiStreams is the initial maximum number of bidirectional streams.
+	MaxBidiStreams int64
+	// MaxUniStreams is the initial maximum number of unidirectional streams.
+	MaxUniStreams int64
+	// MaxDatagramFrameSize is the maximum size of a DATAGRAM frame (RFC 9221).
+	// A value of 0 means that datagrams are not supported.
+	MaxDatagramFrameSize int64
 }
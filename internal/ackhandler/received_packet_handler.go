@@ -21,16 +21,20 @@ type receivedPacketHandler struct {
 
 var _ ReceivedPacketHandler = &receivedPacketHandler{}
 
+// newReceivedPacketHandler creates a new receivedPacketHandler.
+// The maxAckDelay and packetsBeforeAck only apply to the application data packet number space.
 func newReceivedPacketHandler(
 	sentPackets sentPacketTracker,
 	rttStats *utils.RTTStats,
+	maxAckDelay time.Duration,
+	packetsBeforeAck int,
 	logger utils.Logger,
 ) ReceivedPacketHandler {
 	return &receivedPacketHandler{
 		sentPackets:      sentPackets,
-		initialPackets:   newReceivedPacketTracker(rttStats, logger),
-		handshakePackets: newReceivedPacketTracker(rttStats, logger),
-		appDataPackets:   newReceivedPacketTracker(rttStats, logger),
+		initialPackets:   newReceivedPacketTracker(rttStats, protocol.MaxAckDelay, protocol.PacketsBeforeAck, logger),
+		handshakePackets: newReceivedPacketTracker(rttStats, protocol.MaxAckDelay, protocol.PacketsBeforeAck, logger),
+		appDataPackets:   newReceivedPacketTracker(rttStats, maxAckDelay, packetsBeforeAck, logger),
 		lowest1RTTPacket: protocol.InvalidPacketNumber,
 	}
 }
@@ -21,6 +21,8 @@ var _ = Describe("Received Packet Handler", func() {
 		handler = newReceivedPacketHandler(
 			sentPackets,
 			&utils.RTTStats{},
+			protocol.MaxAckDelay,
+			protocol.PacketsBeforeAck,
 			utils.DefaultLogger,
 		)
 	})
@@ -9,9 +9,6 @@ import (
 	"github.com/quic-go/quic-go/internal/wire"
 )
 
-// number of ack-eliciting packets received before sending an ack.
-const packetsBeforeAck = 2
-
 type receivedPacketTracker struct {
 	largestObserved         protocol.PacketNumber
 	ignoreBelow             protocol.PacketNumber
@@ -20,11 +17,12 @@ type receivedPacketTracker struct {
 
 	packetHistory *receivedPacketHistory
 
-	maxAckDelay time.Duration
-	rttStats    *utils.RTTStats
+	maxAckDelay      time.Duration
+	packetsBeforeAck int
+	rttStats         *utils.RTTStats
 
 	hasNewAck bool // true as soon as we received an ack-eliciting new packet
-	ackQueued bool // true once we received more than 2 (or later in the connection 10) ack-eliciting packets
+	ackQueued bool // true once we received packetsBeforeAck ack-eliciting packets since the last ACK (or another condition requires an immediate ACK)
 
 	ackElicitingPacketsReceivedSinceLastAck int
 	ackAlarm                                time.Time
@@ -35,13 +33,16 @@ type receivedPacketTracker struct {
 
 func newReceivedPacketTracker(
 	rttStats *utils.RTTStats,
+	maxAckDelay time.Duration,
+	packetsBeforeAck int,
 	logger utils.Logger,
 ) *receivedPacketTracker {
 	return &receivedPacketTracker{
-		packetHistory: newReceivedPacketHistory(),
-		maxAckDelay:   protocol.MaxAckDelay,
-		rttStats:      rttStats,
-		logger:        logger,
+		packetHistory:    newReceivedPacketHistory(),
+		maxAckDelay:      maxAckDelay,
+		packetsBeforeAck: packetsBeforeAck,
+		rttStats:         rttStats,
+		logger:           logger,
 	}
 }
 
@@ -130,10 +131,10 @@ func (h *receivedPacketTracker) maybeQueueACK(pn protocol.PacketNumber, rcvTime
 		h.ackQueued = true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK every packetsBeforeAck ack-eliciting packets
+	if h.ackElicitingPacketsReceivedSinceLastAck >= h.packetsBeforeAck {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.packetsBeforeAck)
 		}
 		h.ackQueued = true
 	} else if h.ackAlarm.IsZero() {
@@ -19,7 +19,7 @@ var _ = Describe("Received Packet Tracker", func() {
 
 	BeforeEach(func() {
 		rttStats = &utils.RTTStats{}
-		tracker = newReceivedPacketTracker(rttStats, utils.DefaultLogger)
+		tracker = newReceivedPacketTracker(rttStats, protocol.MaxAckDelay, protocol.PacketsBeforeAck, utils.DefaultLogger)
 	})
 
 	Context("accepting packets", func() {
@@ -104,6 +104,29 @@ var _ = Describe("Received Packet Tracker", func() {
 				}
 			})
 
+			It("queues an ACK after the configured number of ack-eliciting packets", func() {
+				tracker = newReceivedPacketTracker(rttStats, protocol.MaxAckDelay, 5, utils.DefaultLogger)
+				receiveAndAck10Packets()
+				p := protocol.PacketNumber(11)
+				for i := 0; i < 4; i++ {
+					Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					p++
+				}
+				Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("queues an ACK for every ack-eliciting packet", func() {
+				tracker = newReceivedPacketTracker(rttStats, protocol.MaxAckDelay, 1, utils.DefaultLogger)
+				receiveAndAck10Packets()
+				for p := protocol.PacketNumber(11); p < 20; p++ {
+					Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+					Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+				}
+			})
+
 			It("resets the counter when a non-queued ACK frame is generated", func() {
 				receiveAndAck10Packets()
 				rcvTime := time.Now()
@@ -126,6 +149,14 @@ var _ = Describe("Received Packet Tracker", func() {
 				Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(protocol.MaxAckDelay)))
 			})
 
+			It("uses the configured max ack delay", func() {
+				tracker = newReceivedPacketTracker(rttStats, 5*time.Millisecond, protocol.PacketsBeforeAck, utils.DefaultLogger)
+				receiveAndAck10Packets()
+				rcvTime := time.Now()
+				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)).To(Succeed())
+				Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(5 * time.Millisecond)))
+			})
+
 			It("queues an ACK if it was reported missing before", func() {
 				receiveAndAck10Packets()
 				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
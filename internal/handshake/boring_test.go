@@ -0,0 +1,62 @@
+//go:build boringcrypto
+
+package handshake
+
+import (
+	"crypto"
+	"crypto/boring"
+	"crypto/tls"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoringCrypto", func() {
+	It("is enabled", func() {
+		Expect(boring.Enabled()).To(BeTrue())
+	})
+
+	It("seals and opens packets using AES-GCM", func() {
+		for _, id := range []uint16{tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384} {
+			suite := getCipherSuite(id)
+			aead := suite.AEAD(make([]byte, suite.KeyLen), make([]byte, aeadNonceLength))
+			nonce := make([]byte, 8)
+			sealed := aead.Seal(nil, nonce, []byte("foobar"), []byte("aad"))
+			opened, err := aead.Open(nil, nonce, sealed, []byte("aad"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opened).To(Equal([]byte("foobar")))
+			sealed[0] ^= 0xff
+			_, err = aead.Open(nil, nonce, sealed, []byte("aad"))
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("applies AES header protection", func() {
+		// test vector from RFC 9001, section A.2
+		connID := protocol.ParseConnectionID(splitHexString("0x8394c8f03e515708"))
+		sealer, _ := NewInitialAEAD(connID, protocol.PerspectiveClient, protocol.Version1)
+		firstByte := byte(0xc3)
+		pnBytes := splitHexString("00000002")
+		sealer.EncryptHeader(splitHexString("d1b1c98dd7689fb8ec11d242b123dc9b"), &firstByte, pnBytes)
+		Expect(firstByte).To(Equal(byte(0xc0)))
+		Expect(pnBytes).To(Equal(splitHexString("7b9aec34")))
+	})
+
+	It("derives the same keys", func() {
+		// same test vector as in hkdf_test.go, HKDF uses BoringCrypto's HMAC implementation
+		expanded := hkdfExpandLabel(crypto.SHA256, []byte("secret"), []byte("context"), "label", 42)
+		Expect(expanded).To(Equal([]byte{0x78, 0x87, 0x6a, 0xb5, 0x84, 0xa2, 0x26, 0xb7, 0x8, 0x5a, 0x7b, 0x3a, 0x4c, 0xbb, 0x1e, 0xbc, 0x2f, 0x9b, 0x67, 0xd0, 0x6a, 0xa2, 0x24, 0xb4, 0x7d, 0x29, 0x3c, 0x7a, 0xce, 0xc7, 0xc3, 0x74, 0xcd, 0x59, 0x7a, 0xa8, 0x21, 0x5e, 0xe7, 0xca, 0x1, 0xda}))
+	})
+
+	It("seals and opens Initial packets", func() {
+		connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
+		clientSealer, _ := NewInitialAEAD(connID, protocol.PerspectiveClient, protocol.Version1)
+		_, serverOpener := NewInitialAEAD(connID, protocol.PerspectiveServer, protocol.Version1)
+		sealed := clientSealer.Seal(nil, []byte("foobar"), 42, []byte("aad"))
+		opened, err := serverOpener.Open(nil, sealed, 42, []byte("aad"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal([]byte("foobar")))
+	})
+})
@@ -11,6 +11,9 @@ import (
 )
 
 // These cipher suite implementations are copied from the standard library crypto/tls package.
+// AES-GCM is constructed using crypto/aes and crypto/cipher, such that it uses BoringCrypto when building
+// with GOEXPERIMENT=boringcrypto. ChaCha20-Poly1305 is not FIPS-approved, and crypto/tls won't negotiate it
+// when FIPS mode is enforced (using crypto/tls/fipsonly).
 
 const aeadNonceLength = 12
 
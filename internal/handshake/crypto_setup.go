@@ -31,6 +31,8 @@ type cryptoSetup struct {
 	tlsConf *tls.Config
 	conn    *qtls.QUICConn
 
+	allowedCipherSuites []uint16
+
 	events []Event
 
 	version protocol.VersionNumber
@@ -75,6 +77,7 @@ func NewCryptoSetupClient(
 	connID protocol.ConnectionID,
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
+	allowedCipherSuites []uint16,
 	enable0RTT bool,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
@@ -96,6 +99,7 @@ func NewCryptoSetupClient(
 	quicConf := &qtls.QUICConfig{TLSConfig: tlsConf}
 	qtls.SetupConfigForClient(quicConf, cs.marshalDataForSessionState, cs.handleDataFromSessionState)
 	cs.tlsConf = tlsConf
+	cs.allowedCipherSuites = allowedCipherSuites
 
 	cs.conn = qtls.QUICClient(quicConf)
 	cs.conn.SetTransportParameters(cs.ourParams.Marshal(protocol.PerspectiveClient))
@@ -109,6 +113,7 @@ func NewCryptoSetupServer(
 	localAddr, remoteAddr net.Addr,
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
+	allowedCipherSuites []uint16,
 	allow0RTT bool,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
@@ -125,6 +130,7 @@ func NewCryptoSetupServer(
 		version,
 	)
 	cs.allow0RTT = allow0RTT
+	cs.allowedCipherSuites = allowedCipherSuites
 
 	quicConf := &qtls.QUICConfig{TLSConfig: tlsConf}
 	qtls.SetupConfigForServer(quicConf, cs.allow0RTT, cs.getDataForSessionTicket, cs.handleSessionTicket)
@@ -265,9 +271,15 @@ func (h *cryptoSetup) handleEvent(ev qtls.QUICEvent) (done bool, err error) {
 	case qtls.QUICNoEvent:
 		return true, nil
 	case qtls.QUICSetReadSecret:
+		if err := h.checkCipherSuite(ev.Suite); err != nil {
+			return false, err
+		}
 		h.SetReadKey(ev.Level, ev.Suite, ev.Data)
 		return false, nil
 	case qtls.QUICSetWriteSecret:
+		if err := h.checkCipherSuite(ev.Suite); err != nil {
+			return false, err
+		}
 		h.SetWriteKey(ev.Level, ev.Suite, ev.Data)
 		return false, nil
 	case qtls.QUICTransportParameters:
@@ -289,6 +301,21 @@ func (h *cryptoSetup) handleEvent(ev qtls.QUICEvent) (done bool, err error) {
 	}
 }
 
+// checkCipherSuite checks that the negotiated cipher suite is contained in the allowed cipher suites (if set).
+// crypto/tls doesn't allow configuring the TLS 1.3 cipher suites, so we can only check the suite after it was negotiated.
+func (h *cryptoSetup) checkCipherSuite(suiteID uint16) error {
+	if len(h.allowedCipherSuites) == 0 {
+		return nil
+	}
+	for _, id := range h.allowedCipherSuites {
+		if id == suiteID {
+			return nil
+		}
+	}
+	// alert 40 is a handshake_failure
+	return fmt.Errorf("cipher suite %s not allowed by the quic.Config: %w", tls.CipherSuiteName(suiteID), qtls.AlertError(40))
+}
+
 func (h *cryptoSetup) NextEvent() Event {
 	if len(h.events) == 0 {
 		return Event{Kind: EventNoEvent}
@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"math/big"
 	"net"
 	"runtime"
@@ -15,6 +16,7 @@ import (
 	mocktls "github.com/quic-go/quic-go/internal/mocks/tls"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/qtls"
 	"github.com/quic-go/quic-go/internal/testdata"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -69,6 +71,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			protocol.ConnectionID{},
 			&wire.TransportParameters{},
 			tlsConf,
+			nil,
 			false,
 			&utils.RTTStats{},
 			nil,
@@ -90,6 +93,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
 			&wire.TransportParameters{StatelessResetToken: &token},
 			testdata.GetTLSConfig(),
+			nil,
 			false,
 			&utils.RTTStats{},
 			nil,
@@ -257,6 +261,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				protocol.ConnectionID{},
 				clientTransportParameters,
 				clientConf,
+				nil,
 				enable0RTT,
 				clientRTTStats,
 				nil,
@@ -274,6 +279,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
 				serverTransportParameters,
 				serverConf,
+				nil,
 				enable0RTT,
 				serverRTTStats,
 				nil,
@@ -307,6 +313,82 @@ var _ = Describe("Crypto Setup TLS", func() {
 			Expect(serverErr).ToNot(HaveOccurred())
 		})
 
+		Context("restricting cipher suites", func() {
+			handshakeWithCipherSuites := func(clientSuites, serverSuites []uint16) (clientErr, serverErr error) {
+				client := NewCryptoSetupClient(
+					protocol.ConnectionID{},
+					&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+					clientConf,
+					clientSuites,
+					false,
+					&utils.RTTStats{},
+					nil,
+					utils.DefaultLogger.WithPrefix("client"),
+					protocol.Version1,
+				)
+				var token protocol.StatelessResetToken
+				server := NewCryptoSetupServer(
+					protocol.ConnectionID{},
+					&net.UDPAddr{IP: net.IPv6loopback, Port: 1234},
+					&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
+					&wire.TransportParameters{ActiveConnectionIDLimit: 2, StatelessResetToken: &token},
+					serverConf,
+					serverSuites,
+					false,
+					&utils.RTTStats{},
+					nil,
+					utils.DefaultLogger.WithPrefix("server"),
+					protocol.Version1,
+				)
+				_, clientErr, _, serverErr = handshake(client, server)
+				return
+			}
+
+			It("ignores the cipher suites configured on the tls.Config", func() {
+				// these are TLS 1.2 cipher suites, and crypto/tls doesn't apply them to TLS 1.3
+				clientConf.CipherSuites = []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+				serverConf.CipherSuites = []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+				clientErr, serverErr := handshakeWithCipherSuites(nil, nil)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+			})
+
+			It("handshakes if the negotiated cipher suite is allowed by both endpoints", func() {
+				reset := qtls.SetCipherSuite(tls.TLS_AES_128_GCM_SHA256)
+				defer reset()
+				clientErr, serverErr := handshakeWithCipherSuites(
+					[]uint16{tls.TLS_CHACHA20_POLY1305_SHA256, tls.TLS_AES_128_GCM_SHA256},
+					[]uint16{tls.TLS_AES_256_GCM_SHA384, tls.TLS_AES_128_GCM_SHA256},
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+			})
+
+			It("rejects cipher suites not allowed on the server side", func() {
+				reset := qtls.SetCipherSuite(tls.TLS_CHACHA20_POLY1305_SHA256)
+				defer reset()
+				_, serverErr := handshakeWithCipherSuites(
+					[]uint16{tls.TLS_CHACHA20_POLY1305_SHA256},
+					[]uint16{tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384},
+				)
+				var transportErr *qerr.TransportError
+				Expect(errors.As(serverErr, &transportErr)).To(BeTrue())
+				Expect(transportErr.ErrorCode.IsCryptoError()).To(BeTrue())
+				Expect(transportErr.ErrorCode).To(BeEquivalentTo(0x100 + 40))
+				Expect(transportErr.Error()).To(ContainSubstring("cipher suite TLS_CHACHA20_POLY1305_SHA256 not allowed by the quic.Config"))
+			})
+
+			It("rejects cipher suites not allowed on the client side", func() {
+				reset := qtls.SetCipherSuite(tls.TLS_AES_256_GCM_SHA384)
+				defer reset()
+				clientErr, _ := handshakeWithCipherSuites([]uint16{tls.TLS_AES_128_GCM_SHA256}, nil)
+				var transportErr *qerr.TransportError
+				Expect(errors.As(clientErr, &transportErr)).To(BeTrue())
+				Expect(transportErr.ErrorCode).To(BeEquivalentTo(0x100 + 40))
+				Expect(transportErr.Error()).To(ContainSubstring("cipher suite TLS_AES_256_GCM_SHA384 not allowed by the quic.Config"))
+			})
+		})
+
 		It("handshakes with client auth", func() {
 			clientConf.Certificates = []tls.Certificate{generateCert()}
 			serverConf.ClientAuth = tls.RequireAnyClientCert
@@ -326,6 +408,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				protocol.ConnectionID{},
 				cTransportParameters,
 				clientConf,
+				nil,
 				false,
 				&utils.RTTStats{},
 				nil,
@@ -345,6 +428,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
 				sTransportParameters,
 				serverConf,
+				nil,
 				false,
 				&utils.RTTStats{},
 				nil,
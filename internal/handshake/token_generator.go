@@ -5,6 +5,7 @@ import (
 	"encoding/asn1"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -41,7 +42,11 @@ type token struct {
 
 // A TokenGenerator generates tokens
 type TokenGenerator struct {
+	mutex          sync.RWMutex
 	tokenProtector tokenProtector
+	// Set after the key was rotated.
+	// Tokens protected with the previous key are still accepted.
+	prevTokenProtector tokenProtector
 }
 
 // NewTokenGenerator initializes a new TokenGenerator
@@ -65,7 +70,7 @@ func (g *TokenGenerator) NewRetryToken(
 	if err != nil {
 		return nil, err
 	}
-	return g.tokenProtector.NewToken(data)
+	return g.newToken(data)
 }
 
 // NewToken generates a new token to be sent in a NEW_TOKEN frame
@@ -77,9 +82,37 @@ func (g *TokenGenerator) NewToken(raddr net.Addr) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return g.newToken(data)
+}
+
+// RotateKey replaces the key used to protect new tokens.
+// Tokens protected with the previous key are still accepted by DecodeToken,
+// tokens protected with any older key are rejected.
+func (g *TokenGenerator) RotateKey(key TokenProtectorKey) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.prevTokenProtector = g.tokenProtector
+	g.tokenProtector = newTokenProtector(key)
+}
+
+func (g *TokenGenerator) newToken(data []byte) ([]byte, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
 	return g.tokenProtector.NewToken(data)
 }
 
+func (g *TokenGenerator) decodeToken(encrypted []byte) ([]byte, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	data, err := g.tokenProtector.DecodeToken(encrypted)
+	if err != nil && g.prevTokenProtector != nil {
+		return g.prevTokenProtector.DecodeToken(encrypted)
+	}
+	return data, err
+}
+
 // DecodeToken decodes a token
 func (g *TokenGenerator) DecodeToken(encrypted []byte) (*Token, error) {
 	// if the client didn't send any token, DecodeToken will be called with a nil-slice
@@ -87,7 +120,7 @@ func (g *TokenGenerator) DecodeToken(encrypted []byte) (*Token, error) {
 		return nil, nil
 	}
 
-	data, err := g.tokenProtector.DecodeToken(encrypted)
+	data, err := g.decodeToken(encrypted)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +58,33 @@ var _ = Describe("Token Generator", func() {
 		Expect(token.RetrySrcConnectionID).To(Equal(connID2))
 	})
 
+	It("accepts tokens protected with the previous key after rotating the key", func() {
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		token1, err := tokenGen.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		var key TokenProtectorKey
+		rand.Read(key[:])
+		tokenGen.RotateKey(key)
+		token2, err := tokenGen.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		// tokens generated with the current key can't be decoded by a generator using the old key
+		t, err := tokenGen.prevTokenProtector.DecodeToken(token2)
+		Expect(err).To(HaveOccurred())
+		Expect(t).To(BeNil())
+		for _, enc := range [][]byte{token1, token2} {
+			token, err := tokenGen.DecodeToken(enc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.ValidateRemoteAddr(addr)).To(BeTrue())
+		}
+		// rotate again, token1 is now protected with a key that is too old
+		rand.Read(key[:])
+		tokenGen.RotateKey(key)
+		_, err = tokenGen.DecodeToken(token1)
+		Expect(err).To(HaveOccurred())
+		_, err = tokenGen.DecodeToken(token2)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
 	It("rejects invalid tokens", func() {
 		_, err := tokenGen.DecodeToken([]byte("invalid token"))
 		Expect(err).To(HaveOccurred())
@@ -170,9 +170,8 @@ const TimerGranularity = time.Millisecond
 // MaxAckDelay is the maximum time by which we delay sending ACKs.
 const MaxAckDelay = 25 * time.Millisecond
 
-// MaxAckDelayInclGranularity is the max_ack_delay including the timer granularity.
-// This is the value that should be advertised to the peer.
-const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
+// PacketsBeforeAck is the number of ack-eliciting packets received before we send an ACK.
+const PacketsBeforeAck = 2
 
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
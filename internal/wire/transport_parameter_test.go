@@ -3,6 +3,7 @@ package wire
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"time"
@@ -126,6 +127,17 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
 	})
 
+	It("marshals the max_udp_payload_size", func() {
+		data := (&TransportParameters{ActiveConnectionIDLimit: 2}).Marshal(protocol.PerspectiveClient)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+		Expect(p.MaxUDPPayloadSize).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+		data = (&TransportParameters{ActiveConnectionIDLimit: 2, MaxUDPPayloadSize: 1300}).Marshal(protocol.PerspectiveClient)
+		p = &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+		Expect(p.MaxUDPPayloadSize).To(BeEquivalentTo(1300))
+	})
+
 	It("marshals additional transport parameters (used for testing large ClientHellos)", func() {
 		origAdditionalTransportParametersClient := AdditionalTransportParametersClient
 		defer func() {
@@ -369,6 +381,40 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.InitialMaxStreamDataBidiRemote).To(Equal(protocol.ByteCount(0x42)))
 	})
 
+	It("adds a greased parameter", func() {
+		for i := 0; i < 100; i++ {
+			r := bytes.NewReader((&TransportParameters{}).Marshal(protocol.PerspectiveClient))
+			var numGreased int
+			for r.Len() > 0 {
+				id, err := quicvarint.Read(r)
+				Expect(err).ToNot(HaveOccurred())
+				length, err := quicvarint.Read(r)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(length).To(BeNumerically("<=", r.Len()))
+				// reserved transport parameter IDs are of the form 31 * N + 27
+				if id >= 27 && (id-27)%31 == 0 {
+					Expect(length).To(BeNumerically("<", 16))
+					numGreased++
+				}
+				r.Seek(int64(length), io.SeekCurrent)
+			}
+			Expect(numGreased).To(Equal(1))
+		}
+	})
+
+	It("skips greased parameters", func() {
+		b := quicvarint.Append(nil, 31*1337+27)
+		b = quicvarint.Append(b, 4)
+		b = append(b, []byte{1, 2, 3, 4}...)
+		b = quicvarint.Append(b, uint64(initialMaxDataParameterID))
+		b = quicvarint.Append(b, uint64(quicvarint.Len(0x1337)))
+		b = quicvarint.Append(b, 0x1337)
+		b = appendInitialSourceConnectionID(b)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(b, protocol.PerspectiveClient)).To(Succeed())
+		Expect(p.InitialMaxData).To(Equal(protocol.ByteCount(0x1337)))
+	})
+
 	It("rejects duplicate parameters", func() {
 		// write first parameter
 		b := quicvarint.Append(nil, uint64(initialMaxStreamDataBidiLocalParameterID))
@@ -353,7 +353,11 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	// idle_timeout
 	b = p.marshalVarintParam(b, maxIdleTimeoutParameterID, uint64(p.MaxIdleTimeout/time.Millisecond))
 	// max_packet_size
-	b = p.marshalVarintParam(b, maxUDPPayloadSizeParameterID, uint64(protocol.MaxPacketBufferSize))
+	maxUDPPayloadSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
+	if p.MaxUDPPayloadSize != 0 {
+		maxUDPPayloadSize = p.MaxUDPPayloadSize
+	}
+	b = p.marshalVarintParam(b, maxUDPPayloadSizeParameterID, uint64(maxUDPPayloadSize))
 	// max_ack_delay
 	// Only send it if is different from the default value.
 	if p.MaxAckDelay != protocol.DefaultMaxAckDelay {
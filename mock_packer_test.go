@@ -147,6 +147,18 @@ func (mr *MockPackerMockRecorder) PackMTUProbePacket(arg0, arg1, arg2 any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackMTUProbePacket", reflect.TypeOf((*MockPacker)(nil).PackMTUProbePacket), arg0, arg1, arg2)
 }
 
+// SetSpinBit mocks base method.
+func (m *MockPacker) SetSpinBit(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSpinBit", arg0)
+}
+
+// SetSpinBit indicates an expected call of SetSpinBit.
+func (mr *MockPackerMockRecorder) SetSpinBit(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSpinBit", reflect.TypeOf((*MockPacker)(nil).SetSpinBit), arg0)
+}
+
 // SetToken mocks base method.
 func (m *MockPacker) SetToken(arg0 []byte) {
 	m.ctrl.T.Helper()
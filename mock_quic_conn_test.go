@@ -229,6 +229,20 @@ func (mr *MockQUICConnMockRecorder) OpenUniStreamSync(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUniStreamSync", reflect.TypeOf((*MockQUICConn)(nil).OpenUniStreamSync), arg0)
 }
 
+// Ping mocks base method.
+func (m *MockQUICConn) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockQUICConnMockRecorder) Ping(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockQUICConn)(nil).Ping), arg0)
+}
+
 // ReceiveMessage mocks base method.
 func (m *MockQUICConn) ReceiveMessage(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
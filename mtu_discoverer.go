@@ -31,7 +31,7 @@ func getMaxPacketSize(addr net.Addr) protocol.ByteCount {
 	maxSize := protocol.ByteCount(protocol.MinInitialPacketSize)
 	// If this is not a UDP address, we don't know anything about the MTU.
 	// Use the minimum size of an Initial packet as the max packet size.
-	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+	if udpAddr, ok := toUDPAddr(addr); ok {
 		if utils.IsIPv4(udpAddr.IP) {
 			maxSize = protocol.InitialPacketSizeIPv4
 		} else {
@@ -41,6 +41,26 @@ func getMaxPacketSize(addr net.Addr) protocol.ByteCount {
 	return maxSize
 }
 
+// getInitialPacketSize returns the packet size used at the beginning of the connection.
+// If configured, the MaxUDPPayloadSize overrides the conservative default value.
+func getInitialPacketSize(addr net.Addr, conf *Config) protocol.ByteCount {
+	if conf.MaxUDPPayloadSize != 0 {
+		return protocol.ByteCount(conf.MaxUDPPayloadSize)
+	}
+	return getMaxPacketSize(addr)
+}
+
+// getInitialMaxDatagramSize returns the max datagram size the congestion controller is initialized with.
+// The congestion controller doesn't support decreasing this value.
+// If the MaxUDPPayloadSize is configured, the peer might advertise a smaller max_udp_payload_size,
+// so we start with the smallest allowed value, and increase it once we received the transport parameters.
+func getInitialMaxDatagramSize(addr net.Addr, conf *Config) protocol.ByteCount {
+	if conf.MaxUDPPayloadSize != 0 {
+		return protocol.MinInitialPacketSize
+	}
+	return getMaxPacketSize(addr)
+}
+
 type mtuFinder struct {
 	lastProbeTime time.Time
 	mtuIncreased  func(protocol.ByteCount)
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"math/rand"
+	"net"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -116,4 +117,24 @@ var _ = Describe("MTU Discoverer", func() {
 		}
 		Expect(maxDiff).To(BeEquivalentTo(maxMTUDiff))
 	})
+
+	It("uses the configured max UDP payload size as the initial packet size", func() {
+		addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 443}
+		Expect(getInitialPacketSize(addr, &Config{})).To(BeEquivalentTo(protocol.InitialPacketSizeIPv4))
+		Expect(getInitialPacketSize(addr, &Config{MaxUDPPayloadSize: 1400})).To(BeEquivalentTo(1400))
+	})
+
+	It("starts the congestion controller at the minimum size if a max UDP payload size is configured", func() {
+		addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 443}
+		Expect(getInitialMaxDatagramSize(addr, &Config{})).To(BeEquivalentTo(protocol.InitialPacketSizeIPv4))
+		Expect(getInitialMaxDatagramSize(addr, &Config{MaxUDPPayloadSize: 1400})).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+	})
+
+	It("uses the client's address family for proxied connections", func() {
+		addr := &ProxiedAddr{
+			Source: &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 443},
+			Proxy:  &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443},
+		}
+		Expect(getMaxPacketSize(addr)).To(BeEquivalentTo(protocol.InitialPacketSizeIPv6))
+	})
 })
@@ -27,6 +27,7 @@ type packer interface {
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount, v protocol.VersionNumber) (shortHeaderPacket, *packetBuffer, error)
 
 	SetToken([]byte)
+	SetSpinBit(bool)
 }
 
 type sealer interface {
@@ -134,6 +135,8 @@ type packetPacker struct {
 	rand                rand.Rand
 
 	numNonAckElicitingAcks int
+
+	spinBit bool
 }
 
 var _ packer = &packetPacker{}
@@ -606,11 +609,17 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 	if p.datagramQueue != nil {
 		if f := p.datagramQueue.Peek(); f != nil {
 			size := f.Length(v)
-			if size <= maxFrameSize-pl.length {
+			if size <= maxFrameSize-pl.length { // DATAGRAM frame fits
 				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f})
 				pl.length += size
 				p.datagramQueue.Pop()
+			} else if !hasAck {
+				// The DATAGRAM frame doesn't fit, and the packet doesn't contain an ACK.
+				// Drop the frame. There's no point in retrying in the next packet,
+				// as the available packet size is unlikely to increase.
+				p.datagramQueue.Drop()
 			}
+			// If the DATAGRAM frame didn't fit and the packet contained an ACK, we'll try to send it in the next packet.
 		}
 	}
 
@@ -816,6 +825,9 @@ func (p *packetPacker) appendShortHeaderPacket(
 	if err != nil {
 		return shortHeaderPacket{}, err
 	}
+	if p.spinBit {
+		raw[0] |= 0x20
+	}
 	payloadOffset := protocol.ByteCount(len(raw))
 
 	raw, err = p.appendPacketPayload(raw, pl, paddingLen, v)
@@ -898,3 +910,8 @@ func (p *packetPacker) encryptPacket(raw []byte, sealer sealer, pn protocol.Pack
 func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
+
+// SetSpinBit sets the value of the latency spin bit used for all subsequent short header packets.
+func (p *packetPacker) SetSpinBit(spin bool) {
+	p.spinBit = spin
+}
@@ -0,0 +1,150 @@
+package quic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+)
+
+// A ProxiedAddr is the remote address of a connection whose packets were forwarded
+// by a load balancer using the PROXY protocol (version 2).
+// It is returned by Connection.RemoteAddr when Transport.EnableProxyProtocol is set.
+type ProxiedAddr struct {
+	// Source is the address of the client, as reported in the PROXY protocol header.
+	Source *net.UDPAddr
+	// Proxy is the address of the load balancer that forwarded the packet.
+	// All packets sent to the client are sent to this address.
+	Proxy net.Addr
+}
+
+var _ net.Addr = &ProxiedAddr{}
+
+// Network returns the network of the client address.
+func (a *ProxiedAddr) Network() string { return a.Source.Network() }
+
+// String returns the client address.
+func (a *ProxiedAddr) String() string { return a.Source.String() }
+
+// UDPAddr returns the client address.
+// It allows code that only deals with *net.UDPAddr to unwrap the address.
+func (a *ProxiedAddr) UDPAddr() *net.UDPAddr { return a.Source }
+
+// toUDPAddr returns the UDP address, unwrapping a *ProxiedAddr.
+func toUDPAddr(addr net.Addr) (*net.UDPAddr, bool) {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a, true
+	case *ProxiedAddr:
+		return a.Source, true
+	default:
+		return nil, false
+	}
+}
+
+// The PROXY protocol v2 signature, see section 2.2 of
+// https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt.
+var proxyProtocolSignature = []byte{0x0d, 0x0a, 0x0d, 0x0a, 0x00, 0x0d, 0x0a, 0x51, 0x55, 0x49, 0x54, 0x0a}
+
+const (
+	proxyProtocolHeaderLen = 16 // signature, version and command, address family and protocol, length
+
+	proxyProtocolVersion2    = 0x2
+	proxyProtocolCmdLocal    = 0x0
+	proxyProtocolCmdProxy    = 0x1
+	proxyProtocolFamilyInet  = 0x1
+	proxyProtocolFamilyInet6 = 0x2
+	proxyProtocolDgram       = 0x2
+)
+
+var errInvalidProxyProtocolHeader = errors.New("invalid PROXY protocol header")
+
+// parseProxyProtocolHeader parses the PROXY protocol v2 header at the start of b.
+// It returns the client address (or nil, for LOCAL connections) and the number of bytes consumed.
+func parseProxyProtocolHeader(b []byte) (*net.UDPAddr, int, error) {
+	if len(b) < proxyProtocolHeaderLen || !bytes.Equal(b[:len(proxyProtocolSignature)], proxyProtocolSignature) {
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	verCmd := b[12]
+	if verCmd>>4 != proxyProtocolVersion2 {
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	l := proxyProtocolHeaderLen + int(binary.BigEndian.Uint16(b[14:16]))
+	if len(b) < l {
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	switch verCmd & 0xf {
+	case proxyProtocolCmdLocal:
+		// The packet was sent by the proxy itself, e.g. for health checking.
+		return nil, l, nil
+	case proxyProtocolCmdProxy:
+	default:
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	if b[13]&0xf != proxyProtocolDgram {
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	addrs := b[proxyProtocolHeaderLen:l]
+	var ipLen int
+	switch b[13] >> 4 {
+	case proxyProtocolFamilyInet:
+		ipLen = net.IPv4len
+	case proxyProtocolFamilyInet6:
+		ipLen = net.IPv6len
+	default:
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	// source address, destination address, source port, destination port
+	if len(addrs) < 2*ipLen+4 {
+		return nil, 0, errInvalidProxyProtocolHeader
+	}
+	ip := make(net.IP, ipLen)
+	copy(ip, addrs[:ipLen])
+	port := binary.BigEndian.Uint16(addrs[2*ipLen : 2*ipLen+2])
+	return &net.UDPAddr{IP: ip, Port: int(port)}, l, nil
+}
+
+// The proxyProtocolConn strips the PROXY protocol header from every packet received,
+// and sends packets destined to a ProxiedAddr to the proxy.
+type proxyProtocolConn struct {
+	rawConn
+
+	logger utils.Logger
+}
+
+var _ rawConn = &proxyProtocolConn{}
+
+func newProxyProtocolConn(c rawConn, logger utils.Logger) *proxyProtocolConn {
+	return &proxyProtocolConn{rawConn: c, logger: logger}
+}
+
+func (c *proxyProtocolConn) ReadPacket() (receivedPacket, error) {
+	for {
+		p, err := c.rawConn.ReadPacket()
+		if err != nil {
+			return receivedPacket{}, err
+		}
+		src, l, err := parseProxyProtocolHeader(p.data)
+		if err != nil {
+			// Packets that weren't forwarded by the proxy can't be attributed to any client.
+			c.logger.Debugf("Dropping packet from %s (%d bytes): %s", p.remoteAddr, p.Size(), err)
+			p.buffer.Release()
+			continue
+		}
+		p.data = p.data[l:]
+		if src != nil {
+			p.remoteAddr = &ProxiedAddr{Source: src, Proxy: p.remoteAddr}
+		}
+		return p, nil
+	}
+}
+
+func (c *proxyProtocolConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN, dscp uint8) (int, error) {
+	if a, ok := addr.(*ProxiedAddr); ok {
+		addr = a.Proxy
+	}
+	return c.rawConn.WritePacket(b, addr, packetInfoOOB, gsoSize, ecn, dscp)
+}
@@ -0,0 +1,181 @@
+package quic
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("PROXY protocol", func() {
+	appendHeader := func(b []byte, cmd, family byte, addrs []byte) []byte {
+		b = append(b, proxyProtocolSignature...)
+		b = append(b, proxyProtocolVersion2<<4|cmd, family<<4|proxyProtocolDgram)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(addrs)))
+		return append(b, addrs...)
+	}
+
+	ipv4Addrs := []byte{
+		1, 2, 3, 4, // source address
+		5, 6, 7, 8, // destination address
+		0x12, 0x34, // source port
+		0x01, 0xbb, // destination port
+	}
+
+	Context("parsing the header", func() {
+		It("parses an IPv4 header", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs)
+			addr, l, err := parseProxyProtocolHeader(append(b, []byte("foobar")...))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(addr.IP.Equal(net.IPv4(1, 2, 3, 4))).To(BeTrue())
+			Expect(addr.Port).To(Equal(0x1234))
+		})
+
+		It("parses an IPv6 header", func() {
+			src := net.ParseIP("2001:db8::1")
+			dst := net.ParseIP("2001:db8::2")
+			addrs := append(append([]byte(src), dst...), 0x12, 0x34, 0x01, 0xbb)
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet6, addrs)
+			addr, l, err := parseProxyProtocolHeader(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(addr.IP.Equal(src)).To(BeTrue())
+			Expect(addr.Port).To(Equal(0x1234))
+		})
+
+		It("skips TLVs", func() {
+			addrs := append(append([]byte{}, ipv4Addrs...), 0x04 /* PP2_TYPE_NOOP */, 0, 2, 0, 0)
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, addrs)
+			addr, l, err := parseProxyProtocolHeader(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(addr.Port).To(Equal(0x1234))
+		})
+
+		It("parses LOCAL headers", func() {
+			b := appendHeader(nil, proxyProtocolCmdLocal, 0, nil)
+			addr, l, err := parseProxyProtocolHeader(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(addr).To(BeNil())
+		})
+
+		It("errors on an invalid signature", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs)
+			b[3] = 0x42
+			_, _, err := parseProxyProtocolHeader(b)
+			Expect(err).To(MatchError(errInvalidProxyProtocolHeader))
+		})
+
+		It("errors on an unknown version", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs)
+			b[12] = 0x1<<4 | proxyProtocolCmdProxy
+			_, _, err := parseProxyProtocolHeader(b)
+			Expect(err).To(MatchError(errInvalidProxyProtocolHeader))
+		})
+
+		It("errors on stream protocols", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs)
+			b[13] = proxyProtocolFamilyInet<<4 | 0x1
+			_, _, err := parseProxyProtocolHeader(b)
+			Expect(err).To(MatchError(errInvalidProxyProtocolHeader))
+		})
+
+		It("errors on unsupported address families", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, 0x3 /* AF_UNIX */, make([]byte, 216))
+			_, _, err := parseProxyProtocolHeader(b)
+			Expect(err).To(MatchError(errInvalidProxyProtocolHeader))
+		})
+
+		It("errors on short headers", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs)
+			for i := 0; i < len(b); i++ {
+				_, _, err := parseProxyProtocolHeader(b[:i])
+				Expect(err).To(MatchError(errInvalidProxyProtocolHeader))
+			}
+		})
+
+		It("errors when the address block is too short", func() {
+			b := appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs[:8])
+			_, _, err := parseProxyProtocolHeader(b)
+			Expect(err).To(MatchError(errInvalidProxyProtocolHeader))
+		})
+	})
+
+	Context("conn", func() {
+		proxyAddr := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4321}
+
+		newPacket := func(data []byte) receivedPacket {
+			buf := getPacketBuffer()
+			buf.Data = append(buf.Data[:0], data...)
+			return receivedPacket{buffer: buf, data: buf.Data, remoteAddr: proxyAddr}
+		}
+
+		It("strips the header", func() {
+			rawConn := NewMockRawConn(mockCtrl)
+			rawConn.EXPECT().ReadPacket().Return(newPacket(append(appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs), []byte("foobar")...)), nil)
+			c := newProxyProtocolConn(rawConn, utils.DefaultLogger)
+			p, err := c.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.data).To(Equal([]byte("foobar")))
+			Expect(p.remoteAddr).To(BeAssignableToTypeOf(&ProxiedAddr{}))
+			addr := p.remoteAddr.(*ProxiedAddr)
+			Expect(addr.String()).To(Equal("1.2.3.4:4660"))
+			Expect(addr.Proxy).To(Equal(proxyAddr))
+		})
+
+		It("doesn't change the remote address for LOCAL connections", func() {
+			rawConn := NewMockRawConn(mockCtrl)
+			rawConn.EXPECT().ReadPacket().Return(newPacket(append(appendHeader(nil, proxyProtocolCmdLocal, 0, nil), []byte("foobar")...)), nil)
+			c := newProxyProtocolConn(rawConn, utils.DefaultLogger)
+			p, err := c.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.data).To(Equal([]byte("foobar")))
+			Expect(p.remoteAddr).To(Equal(proxyAddr))
+		})
+
+		It("drops packets without a header", func() {
+			rawConn := NewMockRawConn(mockCtrl)
+			gomock.InOrder(
+				rawConn.EXPECT().ReadPacket().Return(newPacket([]byte("foobar")), nil),
+				rawConn.EXPECT().ReadPacket().Return(newPacket(append(appendHeader(nil, proxyProtocolCmdProxy, proxyProtocolFamilyInet, ipv4Addrs), []byte("raboof")...)), nil),
+			)
+			c := newProxyProtocolConn(rawConn, utils.DefaultLogger)
+			p, err := c.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.data).To(Equal([]byte("raboof")))
+		})
+
+		It("returns read errors", func() {
+			rawConn := NewMockRawConn(mockCtrl)
+			rawConn.EXPECT().ReadPacket().Return(receivedPacket{}, net.ErrClosed)
+			c := newProxyProtocolConn(rawConn, utils.DefaultLogger)
+			_, err := c.ReadPacket()
+			Expect(err).To(MatchError(net.ErrClosed))
+		})
+
+		It("sends packets to the proxy", func() {
+			rawConn := NewMockRawConn(mockCtrl)
+			c := newProxyProtocolConn(rawConn, utils.DefaultLogger)
+			addr := &ProxiedAddr{Source: &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}, Proxy: proxyAddr}
+			rawConn.EXPECT().WritePacket([]byte("foobar"), proxyAddr, []byte("oob"), uint16(0), protocol.ECT0, uint8(0x2e)).Return(6, nil)
+			n, err := c.WritePacket([]byte("foobar"), addr, []byte("oob"), 0, protocol.ECT0, 0x2e)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(6))
+		})
+
+		It("sends packets to other addresses", func() {
+			rawConn := NewMockRawConn(mockCtrl)
+			c := newProxyProtocolConn(rawConn, utils.DefaultLogger)
+			rawConn.EXPECT().WritePacket([]byte("foobar"), proxyAddr, nil, uint16(0), protocol.ECNUnsupported, uint8(0)).Return(6, nil)
+			_, err := c.WritePacket([]byte("foobar"), proxyAddr, nil, 0, protocol.ECNUnsupported, 0)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -215,13 +215,26 @@ func (t *connectionTracer) recordEvent(eventTime time.Time, details eventDetails
 	}
 }
 
+// toUDPAddr returns the UDP address.
+// It unwraps addresses that wrap a UDP address (e.g. the quic.ProxiedAddr).
+func toUDPAddr(addr net.Addr) (*net.UDPAddr, bool) {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a, true
+	case interface{ UDPAddr() *net.UDPAddr }:
+		return a.UDPAddr(), true
+	default:
+		return nil, false
+	}
+}
+
 func (t *connectionTracer) StartedConnection(local, remote net.Addr, srcConnID, destConnID protocol.ConnectionID) {
 	// ignore this event if we're not dealing with UDP addresses here
-	localAddr, ok := local.(*net.UDPAddr)
+	localAddr, ok := toUDPAddr(local)
 	if !ok {
 		return
 	}
-	remoteAddr, ok := remote.(*net.UDPAddr)
+	remoteAddr, ok := toUDPAddr(remote)
 	if !ok {
 		return
 	}
@@ -166,6 +166,23 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("dst_cid", "05060708"))
 			})
 
+			It("records connection starts for proxied connections", func() {
+				tracer.StartedConnection(
+					&net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 42},
+					&quic.ProxiedAddr{
+						Source: &net.UDPAddr{IP: net.IPv4(192, 168, 12, 34), Port: 24},
+						Proxy:  &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443},
+					},
+					protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+					protocol.ParseConnectionID([]byte{5, 6, 7, 8}),
+				)
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("transport:connection_started"))
+				ev := entry.Event
+				Expect(ev).To(HaveKeyWithValue("dst_ip", "192.168.12.34"))
+				Expect(ev).To(HaveKeyWithValue("dst_port", float64(24)))
+			})
+
 			It("records the version, if no version negotiation happened", func() {
 				tracer.NegotiatedVersion(0x1337, nil, nil)
 				entry := exportAndParseSingle()
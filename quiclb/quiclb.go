@@ -0,0 +1,188 @@
+// Package quiclb implements connection IDs that can be routed by a QUIC-LB compatible load balancer,
+// as described in draft-ietf-quic-load-balancers.
+// The server ID is encoded in the connection ID, such that the load balancer can route packets to the
+// correct server, even after a rebalancing of the load balancer pool.
+//
+// Connection IDs can either be sent in plaintext, or encrypted using AES-128.
+// Encryption is only supported if the server ID and the nonce have a combined length of 16 bytes
+// (the single-pass encryption algorithm). The four-pass algorithm is not implemented.
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	// MaxConfigID is the largest config ID.
+	// The config ID 0b111 is reserved for unroutable connection IDs.
+	MaxConfigID = 6
+	// MinNonceLen is the minimum length of the nonce.
+	MinNonceLen = 4
+
+	maxConnIDLen = 20
+	keyLen       = 16
+)
+
+// A Config is the configuration shared by the load balancer and the servers.
+type Config struct {
+	// ConfigID is encoded in the first 3 bits of the connection ID.
+	// It allows the rotation of configurations.
+	// Valid values are 0 to MaxConfigID.
+	ConfigID uint8
+	// ServerIDLen is the length of the server ID, in bytes.
+	ServerIDLen int
+	// NonceLen is the length of the nonce, in bytes.
+	// It must be at least MinNonceLen.
+	NonceLen int
+	// Key, if set, is used to encrypt the server ID and the nonce.
+	// It must be 16 bytes long, and ServerIDLen + NonceLen must equal 16.
+	Key []byte
+	// LengthSelfDescribing encodes the length of the connection ID in the first octet.
+	// This is useful for load balancers that need to parse short header packets.
+	LengthSelfDescribing bool
+}
+
+func (c *Config) connIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *Config) validate() error {
+	if c.ConfigID > MaxConfigID {
+		return fmt.Errorf("quiclb: invalid config ID: %d", c.ConfigID)
+	}
+	if c.ServerIDLen < 1 {
+		return fmt.Errorf("quiclb: invalid server ID length: %d", c.ServerIDLen)
+	}
+	if c.NonceLen < MinNonceLen {
+		return fmt.Errorf("quiclb: nonce too short: %d (minimum %d)", c.NonceLen, MinNonceLen)
+	}
+	if l := c.connIDLen(); l > maxConnIDLen {
+		return fmt.Errorf("quiclb: connection ID too long: %d (maximum %d)", l, maxConnIDLen)
+	}
+	if c.Key != nil {
+		if len(c.Key) != keyLen {
+			return fmt.Errorf("quiclb: invalid key length: %d", len(c.Key))
+		}
+		if c.ServerIDLen+c.NonceLen != aes.BlockSize {
+			return errors.New("quiclb: encryption requires the server ID and the nonce to have a combined length of 16 bytes")
+		}
+	}
+	return nil
+}
+
+func (c *Config) newBlock() (cipher.Block, error) {
+	if c.Key == nil {
+		return nil, nil
+	}
+	return aes.NewCipher(c.Key)
+}
+
+// A Generator generates connection IDs that encode the server ID.
+// It implements the quic.ConnectionIDGenerator interface.
+type Generator struct {
+	config   Config
+	serverID []byte
+	block    cipher.Block // nil if no key is configured
+
+	rand io.Reader
+}
+
+var _ quic.ConnectionIDGenerator = &Generator{}
+
+// NewGenerator creates a new Generator for the server with the given server ID.
+func NewGenerator(conf *Config, serverID []byte) (*Generator, error) {
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+	if len(serverID) != conf.ServerIDLen {
+		return nil, fmt.Errorf("quiclb: invalid server ID length: %d (expected %d)", len(serverID), conf.ServerIDLen)
+	}
+	block, err := conf.newBlock()
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{
+		config:   *conf,
+		serverID: append([]byte{}, serverID...),
+		block:    block,
+		rand:     rand.Reader,
+	}, nil
+}
+
+// GenerateConnectionID generates a new connection ID.
+func (g *Generator) GenerateConnectionID() (quic.ConnectionID, error) {
+	b := make([]byte, g.config.connIDLen())
+	if _, err := io.ReadFull(g.rand, b); err != nil {
+		return quic.ConnectionID{}, err
+	}
+	if g.config.LengthSelfDescribing {
+		b[0] = g.config.ConfigID<<5 | byte(len(b)-1)&0x1f
+	} else {
+		// Keep the random bits, they must not reveal anything about the (encrypted) nonce.
+		b[0] = g.config.ConfigID<<5 | b[0]&0x1f
+	}
+	copy(b[1:], g.serverID)
+	if g.block != nil {
+		g.block.Encrypt(b[1:], b[1:])
+	}
+	return quic.ConnectionIDFromBytes(b), nil
+}
+
+// ConnectionIDLen returns the length of the connection IDs generated by this Generator.
+func (g *Generator) ConnectionIDLen() int {
+	return g.config.connIDLen()
+}
+
+// A Decoder extracts the server ID from connection IDs.
+// It is used by the load balancer.
+type Decoder struct {
+	config Config
+	block  cipher.Block // nil if no key is configured
+}
+
+// NewDecoder creates a new Decoder.
+func NewDecoder(conf *Config) (*Decoder, error) {
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+	block, err := conf.newBlock()
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{config: *conf, block: block}, nil
+}
+
+// ConfigID returns the config ID encoded in the first octet of a connection ID.
+// Load balancers can use it to select the Decoder.
+func ConfigID(connID []byte) (uint8, error) {
+	if len(connID) == 0 {
+		return 0, errors.New("quiclb: empty connection ID")
+	}
+	return connID[0] >> 5, nil
+}
+
+// ServerID decodes the server ID from the connection ID.
+func (d *Decoder) ServerID(connID []byte) ([]byte, error) {
+	if len(connID) != d.config.connIDLen() {
+		return nil, fmt.Errorf("quiclb: invalid connection ID length: %d (expected %d)", len(connID), d.config.connIDLen())
+	}
+	if configID, _ := ConfigID(connID); configID != d.config.ConfigID {
+		return nil, fmt.Errorf("quiclb: unexpected config ID: %d (expected %d)", configID, d.config.ConfigID)
+	}
+	serverID := make([]byte, d.config.ServerIDLen)
+	if d.block == nil {
+		copy(serverID, connID[1:])
+		return serverID, nil
+	}
+	plaintext := make([]byte, aes.BlockSize)
+	d.block.Decrypt(plaintext, connID[1:])
+	copy(serverID, plaintext)
+	return serverID, nil
+}
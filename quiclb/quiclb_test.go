@@ -0,0 +1,131 @@
+package quiclb
+
+import (
+	"bytes"
+	"crypto/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QUIC-LB", func() {
+	serverID := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	It("validates the config", func() {
+		_, err := NewGenerator(&Config{ConfigID: 7, ServerIDLen: 4, NonceLen: 8}, serverID)
+		Expect(err).To(MatchError("quiclb: invalid config ID: 7"))
+		_, err = NewGenerator(&Config{ServerIDLen: 0, NonceLen: 8}, nil)
+		Expect(err).To(MatchError("quiclb: invalid server ID length: 0"))
+		_, err = NewGenerator(&Config{ServerIDLen: 4, NonceLen: 3}, serverID)
+		Expect(err).To(MatchError("quiclb: nonce too short: 3 (minimum 4)"))
+		_, err = NewGenerator(&Config{ServerIDLen: 4, NonceLen: 16}, serverID)
+		Expect(err).To(MatchError("quiclb: connection ID too long: 21 (maximum 20)"))
+		_, err = NewGenerator(&Config{ServerIDLen: 4, NonceLen: 12, Key: make([]byte, 15)}, serverID)
+		Expect(err).To(MatchError("quiclb: invalid key length: 15"))
+		_, err = NewGenerator(&Config{ServerIDLen: 4, NonceLen: 8, Key: make([]byte, 16)}, serverID)
+		Expect(err).To(MatchError("quiclb: encryption requires the server ID and the nonce to have a combined length of 16 bytes"))
+		_, err = NewGenerator(&Config{ServerIDLen: 4, NonceLen: 8}, []byte{1, 2, 3})
+		Expect(err).To(MatchError("quiclb: invalid server ID length: 3 (expected 4)"))
+	})
+
+	It("generates plaintext connection IDs", func() {
+		conf := &Config{ConfigID: 5, ServerIDLen: 4, NonceLen: 8}
+		g, err := NewGenerator(conf, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(13))
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(13))
+		b := connID.Bytes()
+		Expect(b[0] >> 5).To(BeEquivalentTo(5))
+		Expect(b[1:5]).To(Equal(serverID))
+
+		d, err := NewDecoder(conf)
+		Expect(err).ToNot(HaveOccurred())
+		id, err := d.ServerID(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal(serverID))
+	})
+
+	It("uses a different nonce for every connection ID", func() {
+		g, err := NewGenerator(&Config{ServerIDLen: 4, NonceLen: 8}, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		connID1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		connID2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID1.Bytes()[5:]).ToNot(Equal(connID2.Bytes()[5:]))
+	})
+
+	It("uses independent random bits in the first byte", func() {
+		g, err := NewGenerator(&Config{ConfigID: 3, ServerIDLen: 4, NonceLen: 8}, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		random := bytes.Repeat([]byte{0xff}, 13)
+		random[0] = 0x0a
+		g.rand = bytes.NewReader(random)
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		b := connID.Bytes()
+		Expect(b[0]).To(Equal(byte(3<<5 | 0x0a)))
+		Expect(b[5:]).To(Equal(bytes.Repeat([]byte{0xff}, 8)))
+	})
+
+	It("encodes the length", func() {
+		g, err := NewGenerator(&Config{ConfigID: 1, ServerIDLen: 4, NonceLen: 8, LengthSelfDescribing: true}, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(connID.Bytes()[0]).To(Equal(byte(1<<5 | 12)))
+		}
+	})
+
+	It("generates encrypted connection IDs", func() {
+		key := make([]byte, 16)
+		rand.Read(key)
+		conf := &Config{ConfigID: 2, ServerIDLen: 4, NonceLen: 12, Key: key}
+		g, err := NewGenerator(conf, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(17))
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		b := connID.Bytes()
+		Expect(b[0] >> 5).To(BeEquivalentTo(2))
+		Expect(bytes.Contains(b, serverID)).To(BeFalse())
+
+		d, err := NewDecoder(conf)
+		Expect(err).ToNot(HaveOccurred())
+		id, err := d.ServerID(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal(serverID))
+
+		// a decoder using a different key decodes a different server ID
+		rand.Read(key)
+		d, err = NewDecoder(&Config{ConfigID: 2, ServerIDLen: 4, NonceLen: 12, Key: key})
+		Expect(err).ToNot(HaveOccurred())
+		id, err = d.ServerID(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).ToNot(Equal(serverID))
+	})
+
+	It("rejects connection IDs with the wrong length", func() {
+		d, err := NewDecoder(&Config{ServerIDLen: 4, NonceLen: 8})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.ServerID(make([]byte, 12))
+		Expect(err).To(MatchError("quiclb: invalid connection ID length: 12 (expected 13)"))
+	})
+
+	It("rejects connection IDs with a different config ID", func() {
+		g, err := NewGenerator(&Config{ConfigID: 3, ServerIDLen: 4, NonceLen: 8}, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		configID, err := ConfigID(connID.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configID).To(BeEquivalentTo(3))
+		d, err := NewDecoder(&Config{ConfigID: 4, ServerIDLen: 4, NonceLen: 8})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.ServerID(connID.Bytes())
+		Expect(err).To(MatchError("quiclb: unexpected config ID: 3 (expected 4)"))
+	})
+})
@@ -17,10 +17,12 @@ type retransmissionQueue struct {
 	handshakeCryptoData []*wire.CryptoFrame
 
 	appData []wire.Frame
+
+	onAppDataPingAcked func() // may be nil
 }
 
-func newRetransmissionQueue() *retransmissionQueue {
-	return &retransmissionQueue{}
+func newRetransmissionQueue(onAppDataPingAcked func()) *retransmissionQueue {
+	return &retransmissionQueue{onAppDataPingAcked: onAppDataPingAcked}
 }
 
 // AddPing queues a ping.
@@ -174,7 +176,11 @@ func (q *retransmissionQueueHandshakeAckHandler) OnLost(f wire.Frame) {
 
 type retransmissionQueueAppDataAckHandler retransmissionQueue
 
-func (q *retransmissionQueueAppDataAckHandler) OnAcked(wire.Frame) {}
+func (q *retransmissionQueueAppDataAckHandler) OnAcked(f wire.Frame) {
+	if _, ok := f.(*wire.PingFrame); ok && q.onAppDataPingAcked != nil {
+		q.onAppDataPingAcked()
+	}
+}
 func (q *retransmissionQueueAppDataAckHandler) OnLost(f wire.Frame) {
 	(*retransmissionQueue)(q).addAppData(f)
 }
@@ -12,7 +12,7 @@ var _ = Describe("Retransmission queue", func() {
 	var q *retransmissionQueue
 
 	BeforeEach(func() {
-		q = newRetransmissionQueue()
+		q = newRetransmissionQueue(nil)
 	})
 
 	Context("Initial data", func() {
@@ -220,5 +220,14 @@ var _ = Describe("Retransmission queue", func() {
 			Expect(q.HasAppData()).To(BeTrue())
 			Expect(q.GetAppDataFrame(protocol.MaxByteCount, protocol.Version1)).To(Equal(&wire.PingFrame{}))
 		})
+
+		It("calls the callback when a PING frame is acknowledged", func() {
+			var acked int
+			q = newRetransmissionQueue(func() { acked++ })
+			q.AppDataAckHandler().OnAcked(&wire.MaxDataFrame{MaximumData: 0x42})
+			Expect(acked).To(BeZero())
+			q.AppDataAckHandler().OnAcked(&wire.PingFrame{})
+			Expect(acked).To(Equal(1))
+		})
 	})
 })
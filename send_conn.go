@@ -26,13 +26,14 @@ type sconn struct {
 	logger utils.Logger
 
 	packetInfoOOB []byte
+	dscp          uint8
 	// If GSO enabled, and we receive a GSO error for this remote address, GSO is disabled.
 	gotGSOError bool
 }
 
 var _ sendConn = &sconn{}
 
-func newSendConn(c rawConn, remote net.Addr, info packetInfo, logger utils.Logger) *sconn {
+func newSendConn(c rawConn, remote net.Addr, info packetInfo, dscp uint8, logger utils.Logger) *sconn {
 	localAddr := c.LocalAddr()
 	if info.addr.IsValid() {
 		if udpAddr, ok := localAddr.(*net.UDPAddr); ok {
@@ -51,12 +52,13 @@ func newSendConn(c rawConn, remote net.Addr, info packetInfo, logger utils.Logge
 		localAddr:     localAddr,
 		remoteAddr:    remote,
 		packetInfoOOB: oob,
+		dscp:          dscp,
 		logger:        logger,
 	}
 }
 
 func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
-	_, err := c.WritePacket(p, c.remoteAddr, c.packetInfoOOB, gsoSize, ecn)
+	_, err := c.WritePacket(p, c.remoteAddr, c.packetInfoOOB, gsoSize, ecn, c.dscp)
 	if err != nil && isGSOError(err) {
 		// disable GSO for future calls
 		c.gotGSOError = true
@@ -69,7 +71,7 @@ func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
 			if l > int(gsoSize) {
 				l = int(gsoSize)
 			}
-			if _, err := c.WritePacket(p[:l], c.remoteAddr, c.packetInfoOOB, 0, ecn); err != nil {
+			if _, err := c.WritePacket(p[:l], c.remoteAddr, c.packetInfoOOB, 0, ecn, c.dscp); err != nil {
 				return err
 			}
 			p = p[l:]
@@ -116,6 +116,8 @@ type baseServer struct {
 	connQueue    chan quicConn
 	connQueueLen int32 // to be used as an atomic
 
+	clientLimiter *clientLimiter // only set if MaxHandshakesPerIP or MaxConnectionsPerIP is configured
+
 	tracer *logging.Tracer
 
 	logger utils.Logger
@@ -170,7 +172,7 @@ func (l *EarlyListener) Addr() net.Addr {
 // ListenAddr creates a QUIC server listening on a given address.
 // See Listen for more details.
 func ListenAddr(addr string, tlsConf *tls.Config, config *Config) (*Listener, error) {
-	conn, err := listenUDP(addr)
+	conn, err := listenUDP(addr, config)
 	if err != nil {
 		return nil, err
 	}
@@ -183,7 +185,7 @@ func ListenAddr(addr string, tlsConf *tls.Config, config *Config) (*Listener, er
 
 // ListenAddrEarly works like ListenAddr, but it returns connections before the handshake completes.
 func ListenAddrEarly(addr string, tlsConf *tls.Config, config *Config) (*EarlyListener, error) {
-	conn, err := listenUDP(addr)
+	conn, err := listenUDP(addr, config)
 	if err != nil {
 		return nil, err
 	}
@@ -194,12 +196,20 @@ func ListenAddrEarly(addr string, tlsConf *tls.Config, config *Config) (*EarlyLi
 	}).ListenEarly(tlsConf, config)
 }
 
-func listenUDP(addr string) (*net.UDPConn, error) {
+func listenUDP(addr string, config *Config) (*net.UDPConn, error) {
 	udpAddr, err := net.ResolveUDPAddr("udp", addr)
 	if err != nil {
 		return nil, err
 	}
-	return net.ListenUDP("udp", udpAddr)
+	if config == nil || config.Control == nil {
+		return net.ListenUDP("udp", udpAddr)
+	}
+	lc := net.ListenConfig{Control: config.Control}
+	conn, err := lc.ListenPacket(context.Background(), "udp", udpAddr.String())
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
 }
 
 // Listen listens for QUIC connections on a given net.PacketConn.
@@ -234,7 +244,7 @@ func newServer(
 	config *Config,
 	tracer *logging.Tracer,
 	onClose func(),
-	tokenGeneratorKey TokenGeneratorKey,
+	tokenGenerator *handshake.TokenGenerator,
 	maxTokenAge time.Duration,
 	disableVersionNegotiation bool,
 	acceptEarly bool,
@@ -243,7 +253,7 @@ func newServer(
 		conn:                      conn,
 		tlsConf:                   tlsConf,
 		config:                    config,
-		tokenGenerator:            handshake.NewTokenGenerator(tokenGeneratorKey),
+		tokenGenerator:            tokenGenerator,
 		maxTokenAge:               maxTokenAge,
 		connIDGenerator:           connIDGenerator,
 		connHandler:               connHandler,
@@ -265,6 +275,9 @@ func newServer(
 	if acceptEarly {
 		s.zeroRTTQueues = map[protocol.ConnectionID]*zeroRTTQueue{}
 	}
+	if config.MaxHandshakesPerIP > 0 || config.MaxConnectionsPerIP > 0 {
+		s.clientLimiter = newClientLimiter(config.MaxHandshakesPerIP, config.MaxConnectionsPerIP)
+	}
 	go s.run()
 	go s.runSendQueue()
 	s.logger.Debugf("Listening for %s connections on %s", conn.LocalAddr().Network(), conn.LocalAddr().String())
@@ -387,6 +400,13 @@ func (s *baseServer) handlePacketImpl(p receivedPacket) bool /* is the buffer st
 	if !wire.IsLongHeaderPacket(p.data[0]) {
 		panic(fmt.Sprintf("misrouted packet: %#v", p.data))
 	}
+	if s.config.AcceptClient != nil && !s.config.AcceptClient(p.remoteAddr) {
+		s.logger.Debugf("Dropping a packet from rejected client %s", p.remoteAddr)
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropDOSPrevention)
+		}
+		return false
+	}
 	v, err := wire.ParseVersion(p.data)
 	// drop the packet if we failed to parse the protocol version
 	if err != nil {
@@ -397,7 +417,7 @@ func (s *baseServer) handlePacketImpl(p receivedPacket) bool /* is the buffer st
 		return false
 	}
 	// send a Version Negotiation Packet if the client is speaking a different protocol version
-	if !protocol.IsSupportedVersion(s.config.Versions, v) {
+	if !s.acceptsVersion(p.remoteAddr, v) {
 		if s.disableVersionNegotiation {
 			return false
 		}
@@ -614,6 +634,25 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		return nil
 	}
 
+	var countHandshake bool
+	if s.clientLimiter != nil {
+		// If configured, clients exceeding the handshake limit need to prove ownership of their address.
+		// Clients that did so are exempt from the handshake limit.
+		sendRetry := s.config.SendRetryOnHandshakeLimit
+		if sendRetry && token == nil && s.clientLimiter.HandshakeLimitReached(p.remoteAddr) {
+			s.logger.Debugf("Handshake limit reached for %s. Sending a Retry.", p.remoteAddr)
+			delete(s.zeroRTTQueues, hdr.DestConnectionID)
+			select {
+			case s.retryQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+			default:
+				// drop packet if we can't send out Retry packets fast enough
+				p.buffer.Release()
+			}
+			return nil
+		}
+		countHandshake = !sendRetry || token == nil
+	}
+
 	if queueLen := atomic.LoadInt32(&s.connQueueLen); queueLen >= protocol.MaxAcceptQueueSize {
 		s.logger.Debugf("Rejecting new connection. Server currently busy. Accept queue length: %d (max %d)", queueLen, protocol.MaxAcceptQueueSize)
 		select {
@@ -629,6 +668,15 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	if err != nil {
 		return err
 	}
+	if s.clientLimiter != nil && !s.clientLimiter.Add(p.remoteAddr, countHandshake) {
+		s.logger.Debugf("Dropping Initial packet. Connection limit reached for %s.", p.remoteAddr)
+		delete(s.zeroRTTQueues, hdr.DestConnectionID)
+		p.buffer.Release()
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+		}
+		return nil
+	}
 	s.logger.Debugf("Changing connection ID to %s.", connID)
 	var conn quicConn
 	tracingID := nextConnTracingID()
@@ -652,7 +700,7 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 			tracer = config.Tracer(context.WithValue(context.Background(), ConnectionTracingKey, tracingID), protocol.PerspectiveServer, connID)
 		}
 		conn = s.newConn(
-			newSendConn(s.conn, p.remoteAddr, p.info, s.logger),
+			newSendConn(s.conn, p.remoteAddr, p.info, config.DSCP, s.logger),
 			s.connHandler,
 			origDestConnID,
 			retrySrcConnID,
@@ -681,6 +729,12 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 
 		return conn, true
 	}); !added {
+		if s.clientLimiter != nil {
+			if countHandshake {
+				s.clientLimiter.HandshakeComplete(p.remoteAddr)
+			}
+			s.clientLimiter.Remove(p.remoteAddr)
+		}
 		select {
 		case s.connectionRefusedQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
 		default:
@@ -690,7 +744,7 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		return nil
 	}
 	go conn.run()
-	go s.handleNewConn(conn)
+	go s.handleNewConn(conn, p.remoteAddr, countHandshake)
 	if conn == nil {
 		p.buffer.Release()
 		return nil
@@ -698,8 +752,34 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	return nil
 }
 
-func (s *baseServer) handleNewConn(conn quicConn) {
+// handleNewConn passes the connection to Accept once it is ready.
+// If the client limiter is used, it also updates the limiter
+// when the handshake completes and when the connection is closed.
+func (s *baseServer) handleNewConn(conn quicConn, remoteAddr net.Addr, countHandshake bool) {
 	connCtx := conn.Context()
+	// only set as long as the handshake counts towards the client's handshake limit
+	var handshakeComplete <-chan struct{}
+	if countHandshake {
+		handshakeComplete = conn.HandshakeComplete()
+	}
+	releaseHandshake := func() {
+		s.clientLimiter.HandshakeComplete(remoteAddr)
+		handshakeComplete = nil
+	}
+	if s.clientLimiter != nil {
+		defer func() {
+			if handshakeComplete != nil {
+				select {
+				case <-handshakeComplete:
+				case <-connCtx.Done():
+				}
+				releaseHandshake()
+			}
+			<-connCtx.Done()
+			s.clientLimiter.Remove(remoteAddr)
+		}()
+	}
+
 	if s.acceptEarlyConns {
 		// wait until the early connection is ready (or the handshake fails)
 		select {
@@ -714,15 +794,25 @@ func (s *baseServer) handleNewConn(conn quicConn) {
 		case <-connCtx.Done():
 			return
 		}
+		if handshakeComplete != nil {
+			releaseHandshake()
+		}
 	}
 
 	atomic.AddInt32(&s.connQueueLen, 1)
-	select {
-	case s.connQueue <- conn:
-		// blocks until the connection is accepted
-	case <-connCtx.Done():
-		atomic.AddInt32(&s.connQueueLen, -1)
-		// don't pass connections that were already closed to Accept()
+	for {
+		select {
+		case s.connQueue <- conn:
+			// blocks until the connection is accepted
+			return
+		case <-handshakeComplete:
+			// the handshake of an early connection completed before it was accepted
+			releaseHandshake()
+		case <-connCtx.Done():
+			atomic.AddInt32(&s.connQueueLen, -1)
+			// don't pass connections that were already closed to Accept()
+			return
+		}
 	}
 }
 
@@ -769,7 +859,7 @@ func (s *baseServer) sendRetryPacket(p rejectedPacket) error {
 	if s.tracer != nil && s.tracer.SentPacket != nil {
 		s.tracer.SentPacket(p.remoteAddr, &replyHdr.Header, protocol.ByteCount(len(buf.Data)), nil)
 	}
-	_, err = s.conn.WritePacket(buf.Data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported)
+	_, err = s.conn.WritePacket(buf.Data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported, 0)
 	return err
 }
 
@@ -854,7 +944,7 @@ func (s *baseServer) sendError(remoteAddr net.Addr, hdr *wire.Header, sealer han
 	if s.tracer != nil && s.tracer.SentPacket != nil {
 		s.tracer.SentPacket(remoteAddr, &replyHdr.Header, protocol.ByteCount(len(b.Data)), []logging.Frame{ccf})
 	}
-	_, err = s.conn.WritePacket(b.Data, remoteAddr, info.OOB(), 0, protocol.ECNUnsupported)
+	_, err = s.conn.WritePacket(b.Data, remoteAddr, info.OOB(), 0, protocol.ECNUnsupported, 0)
 	return err
 }
 
@@ -868,6 +958,14 @@ func (s *baseServer) enqueueVersionNegotiationPacket(p receivedPacket) (bufferIn
 	return false
 }
 
+// acceptsVersion determines if a connection from remoteAddr may use the QUIC version v.
+func (s *baseServer) acceptsVersion(remoteAddr net.Addr, v protocol.VersionNumber) bool {
+	if !protocol.IsSupportedVersion(s.config.Versions, v) {
+		return false
+	}
+	return s.config.AcceptVersion == nil || s.config.AcceptVersion(remoteAddr, v)
+}
+
 func (s *baseServer) maybeSendVersionNegotiationPacket(p receivedPacket) {
 	defer p.buffer.Release()
 
@@ -888,11 +986,20 @@ func (s *baseServer) maybeSendVersionNegotiationPacket(p receivedPacket) {
 
 	s.logger.Debugf("Client offered version %s, sending Version Negotiation", v)
 
-	data := wire.ComposeVersionNegotiation(dest, src, s.config.Versions)
+	versions := s.config.Versions
+	if s.config.AcceptVersion != nil {
+		versions = make([]protocol.VersionNumber, 0, len(s.config.Versions))
+		for _, ver := range s.config.Versions {
+			if s.config.AcceptVersion(p.remoteAddr, ver) {
+				versions = append(versions, ver)
+			}
+		}
+	}
+	data := wire.ComposeVersionNegotiation(dest, src, versions)
 	if s.tracer != nil && s.tracer.SentVersionNegotiationPacket != nil {
-		s.tracer.SentVersionNegotiationPacket(p.remoteAddr, src, dest, s.config.Versions)
+		s.tracer.SentVersionNegotiationPacket(p.remoteAddr, src, dest, versions)
 	}
-	if _, err := s.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported); err != nil {
+	if _, err := s.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported, 0); err != nil {
 		s.logger.Debugf("Error sending Version Negotiation: %s", err)
 	}
 }
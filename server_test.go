@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/handshake"
@@ -156,6 +157,25 @@ var _ = Describe("Server", func() {
 		Expect(ln.Close()).To(Succeed())
 	})
 
+	It("calls the Control function when creating the socket", func() {
+		var network, address string
+		ln, err := ListenAddr("127.0.0.1:0", tlsConf, &Config{
+			Control: func(n, a string, _ syscall.RawConn) error {
+				network, address = n, a
+				return nil
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(network).To(Equal("udp4"))
+		Expect(address).To(Equal("127.0.0.1:0"))
+		Expect(ln.Close()).To(Succeed())
+
+		_, err = ListenAddr("127.0.0.1:0", tlsConf, &Config{
+			Control: func(string, string, syscall.RawConn) error { return errors.New("control error") },
+		})
+		Expect(err).To(MatchError(ContainSubstring("control error")))
+	})
+
 	It("errors if given an invalid address", func() {
 		addr := "127.0.0.1"
 		_, err := ListenAddr(addr, tlsConf, &Config{})
@@ -216,6 +236,27 @@ var _ = Describe("Server", func() {
 				time.Sleep(50 * time.Millisecond)
 			})
 
+			It("drops packets from clients rejected by the AcceptClient callback", func() {
+				raddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+				called := make(chan struct{})
+				serv.config.AcceptClient = func(addr net.Addr) bool {
+					Expect(addr).To(Equal(raddr))
+					close(called)
+					return false
+				}
+				p := getPacket(&wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+					Version:          serv.config.Versions[0],
+				}, make([]byte, protocol.MinInitialPacketSize))
+				p.remoteAddr = raddr
+				tracer.EXPECT().DroppedPacket(raddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropDOSPrevention)
+				serv.handlePacket(p)
+				// make sure there are no Write calls on the packet conn
+				time.Sleep(50 * time.Millisecond)
+				Expect(called).To(BeClosed())
+			})
+
 			It("drops non-Initial packets", func() {
 				p := getPacket(&wire.Header{
 					Type:    protocol.PacketTypeHandshake,
@@ -357,6 +398,69 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("sends a greased version in the Version Negotiation Packet", func() {
+				srcConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5})
+				destConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6})
+				packet := getPacket(&wire.Header{
+					Type:             protocol.PacketTypeHandshake,
+					SrcConnectionID:  srcConnID,
+					DestConnectionID: destConnID,
+					Version:          0x42,
+				}, make([]byte, protocol.MinUnknownVersionPacketSize))
+				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+				packet.remoteAddr = raddr
+				tracer.EXPECT().SentVersionNegotiationPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any())
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					_, _, versions, err := wire.ParseVersionNegotiationPacket(b)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(versions).To(HaveLen(len(serv.config.Versions) + 1))
+					var numGreased int
+					for _, v := range versions {
+						if v&0x0f0f0f0f == 0x0a0a0a0a { // reserved versions are of the form 0x?a?a?a?a
+							numGreased++
+						} else {
+							Expect(serv.config.Versions).To(ContainElement(v))
+						}
+					}
+					Expect(numGreased).To(Equal(1))
+					return len(b), nil
+				})
+				serv.handlePacket(packet)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("sends a Version Negotiation Packet for versions rejected by the AcceptVersion callback", func() {
+				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+				serv.config.Versions = []protocol.VersionNumber{protocol.Version1, protocol.Version2}
+				serv.config.AcceptVersion = func(addr net.Addr, v protocol.VersionNumber) bool {
+					Expect(addr).To(Equal(raddr))
+					return v == protocol.Version1
+				}
+				srcConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5})
+				destConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6})
+				packet := getPacket(&wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  srcConnID,
+					DestConnectionID: destConnID,
+					Version:          protocol.Version2,
+				}, make([]byte, protocol.MinInitialPacketSize))
+				packet.remoteAddr = raddr
+				tracer.EXPECT().SentVersionNegotiationPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), []protocol.VersionNumber{protocol.Version1})
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					_, _, versions, err := wire.ParseVersionNegotiationPacket(b)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(versions).To(ContainElement(protocol.Version1))
+					Expect(versions).ToNot(ContainElement(protocol.Version2))
+					return len(b), nil
+				})
+				serv.handlePacket(packet)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("doesn't send a Version Negotiation packets if sending them is disabled", func() {
 				serv.disableVersionNegotiation = true
 				srcConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5})
@@ -694,6 +798,131 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("drops packets from clients exceeding the connection limit", func() {
+				serv.clientLimiter = newClientLimiter(0, 1)
+				ctx, cancel := context.WithCancel(context.Background())
+				serv.newConn = func(
+					_ sendConn,
+					runner connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ ConnectionIDGenerator,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ bool,
+					_ *logging.ConnectionTracer,
+					_ uint64,
+					_ utils.Logger,
+					_ protocol.VersionNumber,
+				) quicConn {
+					conn := NewMockQUICConn(mockCtrl)
+					conn.EXPECT().handlePacket(gomock.Any())
+					conn.EXPECT().run()
+					conn.EXPECT().Context().Return(ctx)
+					c := make(chan struct{})
+					close(c)
+					conn.EXPECT().HandshakeComplete().Return(c).Times(2)
+					return conn
+				}
+
+				phm.EXPECT().Get(gomock.Any()).Times(2)
+				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() (packetHandler, bool)) bool {
+					phm.EXPECT().GetStatelessResetToken(gomock.Any())
+					_, ok := fn()
+					return ok
+				})
+				serv.handlePacket(getInitialWithRandomDestConnID())
+				p := getInitialWithRandomDestConnID()
+				dropped := make(chan struct{})
+				tracer.EXPECT().DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention).Do(
+					func(net.Addr, logging.PacketType, logging.ByteCount, logging.PacketDropReason) { close(dropped) },
+				)
+				serv.handlePacket(p)
+				Eventually(dropped).Should(BeClosed())
+				// make sure there are no Write calls on the packet conn
+				time.Sleep(50 * time.Millisecond)
+				// once the connection is closed, the client can create a new connection
+				cancel()
+				Eventually(func() bool { return serv.clientLimiter.Add(p.remoteAddr, false) }).Should(BeTrue())
+			})
+
+			It("updates the client limiter when the handshake completes and when the connection is closed", func() {
+				serv.clientLimiter = newClientLimiter(1, 1)
+				ctx, cancel := context.WithCancel(context.Background())
+				handshakeComplete := make(chan struct{})
+				serv.newConn = func(
+					_ sendConn,
+					runner connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ ConnectionIDGenerator,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ bool,
+					_ *logging.ConnectionTracer,
+					_ uint64,
+					_ utils.Logger,
+					_ protocol.VersionNumber,
+				) quicConn {
+					conn := NewMockQUICConn(mockCtrl)
+					conn.EXPECT().handlePacket(gomock.Any())
+					conn.EXPECT().run()
+					conn.EXPECT().Context().Return(ctx)
+					conn.EXPECT().HandshakeComplete().Return(handshakeComplete).Times(2)
+					return conn
+				}
+				phm.EXPECT().Get(gomock.Any())
+				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() (packetHandler, bool)) bool {
+					phm.EXPECT().GetStatelessResetToken(gomock.Any())
+					_, ok := fn()
+					return ok
+				})
+				p := getInitialWithRandomDestConnID()
+				serv.handlePacket(p)
+				Eventually(func() bool { return serv.clientLimiter.HandshakeLimitReached(p.remoteAddr) }).Should(BeTrue())
+				Consistently(func() bool { return serv.clientLimiter.HandshakeLimitReached(p.remoteAddr) }).Should(BeTrue())
+				close(handshakeComplete)
+				Eventually(func() bool { return serv.clientLimiter.HandshakeLimitReached(p.remoteAddr) }).Should(BeFalse())
+				// the connection still counts towards the connection limit
+				Expect(serv.clientLimiter.Add(p.remoteAddr, false)).To(BeFalse())
+				cancel()
+				Eventually(func() bool { return serv.clientLimiter.Add(p.remoteAddr, false) }).Should(BeTrue())
+			})
+
+			It("sends a Retry to clients exceeding the handshake limit, if configured", func() {
+				serv.clientLimiter = newClientLimiter(1, 0)
+				serv.config.SendRetryOnHandshakeLimit = true
+				p := getInitialWithRandomDestConnID()
+				Expect(serv.clientLimiter.Add(p.remoteAddr, true)).To(BeTrue())
+				hdr, _, _, err := wire.ParsePacket(p.data)
+				Expect(err).ToNot(HaveOccurred())
+				tracer.EXPECT().SentPacket(p.remoteAddr, gomock.Any(), gomock.Any(), nil).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, _ []logging.Frame) {
+					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+				})
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					replyHdr := parseHeader(b)
+					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					Expect(replyHdr.DestConnectionID).To(Equal(hdr.SrcConnectionID))
+					Expect(replyHdr.Token).ToNot(BeEmpty())
+					return len(b), nil
+				})
+				phm.EXPECT().Get(hdr.DestConnectionID)
+				serv.handlePacket(p)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("doesn't accept new connections if they were closed in the mean time", func() {
 				p := getInitial(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
 				ctx, cancel := context.WithCancel(context.Background())
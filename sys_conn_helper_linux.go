@@ -5,6 +5,8 @@ package quic
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"net"
 	"net/netip"
 	"os"
 	"strconv"
@@ -43,6 +45,66 @@ func forceSetSendBuffer(c syscall.RawConn, bytes int) error {
 	return serr
 }
 
+// connectUDP connects the socket to the remote address.
+// In contrast to an unconnected socket, the kernel then reports ICMP errors (e.g. port unreachable)
+// when reading from and writing to the socket.
+// It's still possible to pass the remote address when sending packets.
+func connectUDP(c syscall.RawConn, raddr *net.UDPAddr) error {
+	var serr error
+	if err := c.Control(func(fd uintptr) {
+		var sa unix.Sockaddr
+		sa, serr = unix.Getsockname(int(fd))
+		if serr != nil {
+			return
+		}
+		switch sa.(type) {
+		case *unix.SockaddrInet4:
+			ip := raddr.IP.To4()
+			if ip == nil {
+				serr = fmt.Errorf("can't connect IPv4 socket to %s", raddr)
+				return
+			}
+			sa4 := &unix.SockaddrInet4{Port: raddr.Port}
+			copy(sa4.Addr[:], ip)
+			serr = unix.Connect(int(fd), sa4)
+		case *unix.SockaddrInet6:
+			sa6 := &unix.SockaddrInet6{Port: raddr.Port}
+			copy(sa6.Addr[:], raddr.IP.To16())
+			if raddr.Zone != "" {
+				if ifi, err := net.InterfaceByName(raddr.Zone); err == nil {
+					sa6.ZoneId = uint32(ifi.Index)
+				}
+			}
+			serr = unix.Connect(int(fd), sa6)
+		default:
+			serr = fmt.Errorf("unexpected socket address type: %T", sa)
+		}
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
+// disconnectUDP dissolves the association created by connectUDP.
+// Once the connection attempt has succeeded, ICMP errors must not be reported anymore:
+// the kernel would return ECONNREFUSED from the next read, which would close the transport.
+// Any error that is already pending on the socket is cleared.
+func disconnectUDP(c syscall.RawConn) error {
+	var serr error
+	if err := c.Control(func(fd uintptr) {
+		sa := unix.RawSockaddr{Family: unix.AF_UNSPEC}
+		if _, _, errno := unix.Syscall(unix.SYS_CONNECT, fd, uintptr(unsafe.Pointer(&sa)), unsafe.Sizeof(sa)); errno != 0 {
+			serr = errno
+			return
+		}
+		// reading SO_ERROR clears the pending error
+		_, serr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ERROR)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
 func parseIPv4PktInfo(body []byte) (ip netip.Addr, ifIndex uint32, ok bool) {
 	// struct in_pktinfo {
 	// 	unsigned int   ipi_ifindex;  /* Interface index */
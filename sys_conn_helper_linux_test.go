@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net"
 	"os"
+	"time"
 
 	"golang.org/x/sys/unix"
 
@@ -76,3 +77,49 @@ var _ = Describe("forcing a change of send and receive buffer sizes", func() {
 		Expect(isGSOError(errors.New("test"))).To(BeFalse())
 	})
 })
+
+var _ = Describe("connecting UDP sockets", func() {
+	It("reports ICMP errors on a connected socket", func() {
+		// find a port that nobody is listening on
+		l, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		raddr := l.LocalAddr().(*net.UDPAddr)
+		Expect(l.Close()).To(Succeed())
+
+		c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4zero})
+		Expect(err).ToNot(HaveOccurred())
+		defer c.Close()
+		syscallConn, err := c.SyscallConn()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connectUDP(syscallConn, raddr)).To(Succeed())
+
+		// it's still possible to pass the remote address
+		_, err = c.WriteToUDP([]byte("foobar"), raddr)
+		Expect(err).ToNot(HaveOccurred())
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err = c.ReadFromUDP(make([]byte, 100))
+		Expect(err).To(MatchError(unix.ECONNREFUSED))
+	})
+
+	It("doesn't report ICMP errors after disconnecting the socket", func() {
+		// find a port that nobody is listening on
+		l, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		raddr := l.LocalAddr().(*net.UDPAddr)
+		Expect(l.Close()).To(Succeed())
+
+		c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4zero})
+		Expect(err).ToNot(HaveOccurred())
+		defer c.Close()
+		syscallConn, err := c.SyscallConn()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connectUDP(syscallConn, raddr)).To(Succeed())
+		Expect(disconnectUDP(syscallConn)).To(Succeed())
+
+		_, err = c.WriteToUDP([]byte("foobar"), raddr)
+		Expect(err).ToNot(HaveOccurred())
+		c.SetReadDeadline(time.Now().Add(scaleDuration(50 * time.Millisecond)))
+		_, _, err = c.ReadFromUDP(make([]byte, 100))
+		Expect(err).To(MatchError(os.ErrDeadlineExceeded))
+	})
+})
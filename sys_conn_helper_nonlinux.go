@@ -2,8 +2,15 @@
 
 package quic
 
+import "net"
+
 func forceSetReceiveBuffer(c any, bytes int) error { return nil }
 func forceSetSendBuffer(c any, bytes int) error    { return nil }
 
+// connectUDP is only implemented on Linux.
+// On other platforms, passing the remote address when writing to a connected socket fails with EISCONN.
+func connectUDP(any, *net.UDPAddr) error { return nil }
+func disconnectUDP(any) error            { return nil }
+
 func appendUDPSegmentSizeMsg([]byte, uint16) []byte { return nil }
 func isGSOError(error) bool                         { return false }
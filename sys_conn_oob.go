@@ -237,7 +237,7 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 }
 
 // WritePacket writes a new packet.
-func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN) (int, error) {
+func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN, dscp uint8) (int, error) {
 	oob := packetInfoOOB
 	if gsoSize > 0 {
 		if !c.capabilities().GSO {
@@ -245,15 +245,20 @@ func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gso
 		}
 		oob = appendUDPSegmentSizeMsg(oob, gsoSize)
 	}
-	if ecn != protocol.ECNUnsupported {
-		if !c.capabilities().ECN {
-			panic("tried to send a ECN-marked packet although ECN is disabled")
+	if ecn != protocol.ECNUnsupported && !c.capabilities().ECN {
+		panic("tried to send a ECN-marked packet although ECN is disabled")
+	}
+	// The ECN bits and the DSCP share the same byte (the TOS byte for IPv4, the Traffic Class for IPv6).
+	if ecn != protocol.ECNUnsupported || dscp != 0 {
+		tos := dscp << 2
+		if ecn != protocol.ECNUnsupported {
+			tos |= ecn.ToHeaderBits()
 		}
 		if remoteUDPAddr, ok := addr.(*net.UDPAddr); ok {
 			if remoteUDPAddr.IP.To4() != nil {
-				oob = appendIPv4ECNMsg(oob, ecn)
+				oob = appendIPv4TOSMsg(oob, tos)
 			} else {
-				oob = appendIPv6ECNMsg(oob, ecn)
+				oob = appendIPv6TOSMsg(oob, tos)
 			}
 		}
 	}
@@ -301,7 +306,7 @@ func (info *packetInfo) OOB() []byte {
 	return nil
 }
 
-func appendIPv4ECNMsg(b []byte, val protocol.ECN) []byte {
+func appendIPv4TOSMsg(b []byte, tos uint8) []byte {
 	startLen := len(b)
 	b = append(b, make([]byte, unix.CmsgSpace(ecnIPv4DataLen))...)
 	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
@@ -311,11 +316,11 @@ func appendIPv4ECNMsg(b []byte, val protocol.ECN) []byte {
 
 	// UnixRights uses the private `data` method, but I *think* this achieves the same goal.
 	offset := startLen + unix.CmsgSpace(0)
-	b[offset] = val.ToHeaderBits()
+	b[offset] = tos
 	return b
 }
 
-func appendIPv6ECNMsg(b []byte, val protocol.ECN) []byte {
+func appendIPv6TOSMsg(b []byte, tos uint8) []byte {
 	startLen := len(b)
 	const dataLen = 4
 	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
@@ -326,6 +331,6 @@ func appendIPv6ECNMsg(b []byte, val protocol.ECN) []byte {
 
 	// UnixRights uses the private `data` method, but I *think* this achieves the same goal.
 	offset := startLen + unix.CmsgSpace(0)
-	b[offset] = val.ToHeaderBits()
+	b[offset] = tos
 	return b
 }
@@ -149,7 +149,7 @@ var _ = Describe("OOB Conn Test", func() {
 			defer c.Close()
 
 			for _, val := range []protocol.ECN{protocol.ECNNon, protocol.ECT1, protocol.ECT0, protocol.ECNCE} {
-				_, _, err = c.WriteMsgUDP([]byte("foobar"), appendIPv4ECNMsg([]byte{}, val), conn.LocalAddr().(*net.UDPAddr))
+				_, _, err = c.WriteMsgUDP([]byte("foobar"), appendIPv4TOSMsg([]byte{}, val.ToHeaderBits()), conn.LocalAddr().(*net.UDPAddr))
 				Expect(err).ToNot(HaveOccurred())
 				var p receivedPacket
 				Eventually(packetChan).Should(Receive(&p))
@@ -167,7 +167,7 @@ var _ = Describe("OOB Conn Test", func() {
 			defer c.Close()
 
 			for _, val := range []protocol.ECN{protocol.ECNNon, protocol.ECT1, protocol.ECT0, protocol.ECNCE} {
-				_, _, err = c.WriteMsgUDP([]byte("foobar"), appendIPv6ECNMsg([]byte{}, val), conn.LocalAddr().(*net.UDPAddr))
+				_, _, err = c.WriteMsgUDP([]byte("foobar"), appendIPv6TOSMsg([]byte{}, val.ToHeaderBits()), conn.LocalAddr().(*net.UDPAddr))
 				Expect(err).ToNot(HaveOccurred())
 				var p receivedPacket
 				Eventually(packetChan).Should(Receive(&p))
@@ -300,16 +300,49 @@ var _ = Describe("OOB Conn Test", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			oob := make([]byte, 0, 123)
-			oobConn.WritePacket([]byte("foobar"), addr, oob, 0, protocol.ECNCE)
+			oobConn.WritePacket([]byte("foobar"), addr, oob, 0, protocol.ECNCE, 0)
 			Expect(c.oobs).To(HaveLen(1))
 			oobMsg := c.oobs[0]
 			Expect(oobMsg).ToNot(BeEmpty())
 			Expect(oobMsg).To(HaveCap(cap(oob))) // check that it appended to oob
-			expected := appendIPv4ECNMsg([]byte{}, protocol.ECNCE)
+			expected := appendIPv4TOSMsg([]byte{}, protocol.ECNCE.ToHeaderBits())
 			Expect(oobMsg).To(Equal(expected))
 		})
 	})
 
+	Context("sending DSCP-marked packets", func() {
+		It("sets the TOS control message", func() {
+			addr, err := net.ResolveUDPAddr("udp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			udpConn, err := net.ListenUDP("udp", addr)
+			Expect(err).ToNot(HaveOccurred())
+			c := &oobRecordingConn{UDPConn: udpConn}
+			oobConn, err := newConn(c, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			oobConn.WritePacket([]byte("foobar"), addr, nil, 0, protocol.ECNUnsupported, 46)
+			// ECN and DSCP are combined in the same control message
+			oobConn.WritePacket([]byte("foobar"), addr, nil, 0, protocol.ECT0, 46)
+			Expect(c.oobs).To(HaveLen(2))
+			Expect(c.oobs[0]).To(Equal(appendIPv4TOSMsg([]byte{}, 46<<2)))
+			Expect(c.oobs[1]).To(Equal(appendIPv4TOSMsg([]byte{}, 46<<2|protocol.ECT0.ToHeaderBits())))
+		})
+
+		It("doesn't set a control message if neither ECN nor DSCP is used", func() {
+			addr, err := net.ResolveUDPAddr("udp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			udpConn, err := net.ListenUDP("udp", addr)
+			Expect(err).ToNot(HaveOccurred())
+			c := &oobRecordingConn{UDPConn: udpConn}
+			oobConn, err := newConn(c, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			oobConn.WritePacket([]byte("foobar"), addr, nil, 0, protocol.ECNUnsupported, 0)
+			Expect(c.oobs).To(HaveLen(1))
+			Expect(c.oobs[0]).To(BeEmpty())
+		})
+	})
+
 	if platformSupportsGSO {
 		Context("GSO", func() {
 			It("appends the GSO control message", func() {
@@ -323,7 +356,7 @@ var _ = Describe("OOB Conn Test", func() {
 				Expect(oobConn.capabilities().GSO).To(BeTrue())
 
 				oob := make([]byte, 0, 123)
-				oobConn.WritePacket([]byte("foobar"), addr, oob, 3, protocol.ECNCE)
+				oobConn.WritePacket([]byte("foobar"), addr, oob, 3, protocol.ECNCE, 0)
 				Expect(c.oobs).To(HaveLen(1))
 				oobMsg := c.oobs[0]
 				Expect(oobMsg).ToNot(BeEmpty())
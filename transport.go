@@ -10,6 +10,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -63,6 +64,7 @@ type Transport struct {
 	// If no key is configured, a random key will be generated.
 	// If multiple servers are authoritative for the same domain, they should use the same key,
 	// see section 8.1.3 of RFC 9000 for details.
+	// The key can be rotated using RotateTokenGeneratorKey.
 	TokenGeneratorKey *TokenGeneratorKey
 
 	// MaxTokenAge is the maximum age of the resumption token presented during the handshake.
@@ -77,6 +79,16 @@ type Transport struct {
 	// It has no effect for clients.
 	DisableVersionNegotiationPackets bool
 
+	// EnableProxyProtocol enables parsing of the PROXY protocol (version 2) header.
+	// This is useful when running behind a UDP load balancer that prepends this header
+	// to every datagram it forwards.
+	// If enabled, packets that don't start with a valid PROXY protocol header are dropped,
+	// and the RemoteAddr of connections is a *ProxiedAddr, which contains the client's address.
+	// Packets are sent back to the load balancer, without a PROXY protocol header.
+	// Since packets received for outgoing connections don't carry a PROXY protocol header,
+	// the Transport can't be used for dialing when this is enabled.
+	EnableProxyProtocol bool
+
 	// A Tracer traces events that don't belong to a single QUIC connection.
 	Tracer *logging.Tracer
 
@@ -94,6 +106,9 @@ type Transport struct {
 	connIDGenerator ConnectionIDGenerator
 
 	server *baseServer
+	// Set after the TokenGeneratorKey was rotated.
+	// Tokens encrypted with this key are still accepted, even by a newly created server.
+	prevTokenGeneratorKey *TokenGeneratorKey
 
 	conn rawConn
 
@@ -151,6 +166,11 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	if err := t.init(false); err != nil {
 		return nil, err
 	}
+	tokenGenerator := handshake.NewTokenGenerator(*t.TokenGeneratorKey)
+	if t.prevTokenGeneratorKey != nil {
+		tokenGenerator = handshake.NewTokenGenerator(*t.prevTokenGeneratorKey)
+		tokenGenerator.RotateKey(*t.TokenGeneratorKey)
+	}
 	s := newServer(
 		t.conn,
 		t.handlerMap,
@@ -159,7 +179,7 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		conf,
 		t.Tracer,
 		t.closeServer,
-		*t.TokenGeneratorKey,
+		tokenGenerator,
 		t.MaxTokenAge,
 		t.DisableVersionNegotiationPackets,
 		allow0RTT,
@@ -168,17 +188,40 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	return s, nil
 }
 
+// RotateTokenGeneratorKey replaces the TokenGeneratorKey.
+// New tokens are encrypted using the new key.
+// Tokens encrypted with the previous key are still accepted,
+// such that clients don't need to perform address validation again.
+// Tokens encrypted with any older key are rejected.
+// When running multiple servers authoritative for the same domain,
+// the key should be rotated on all of them.
+func (t *Transport) RotateTokenGeneratorKey(key TokenGeneratorKey) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.prevTokenGeneratorKey = t.TokenGeneratorKey
+	t.TokenGeneratorKey = &key
+	if t.server != nil {
+		t.server.tokenGenerator.RotateKey(key)
+	}
+}
+
 // Dial dials a new connection to a remote host (not using 0-RTT).
 func (t *Transport) Dial(ctx context.Context, addr net.Addr, tlsConf *tls.Config, conf *Config) (Connection, error) {
-	return t.dial(ctx, addr, "", tlsConf, conf, false)
+	return t.dial(ctx, ctx, addr, "", tlsConf, conf, false)
 }
 
 // DialEarly dials a new connection, attempting to use 0-RTT if possible.
 func (t *Transport) DialEarly(ctx context.Context, addr net.Addr, tlsConf *tls.Config, conf *Config) (EarlyConnection, error) {
-	return t.dial(ctx, addr, "", tlsConf, conf, true)
+	return t.dial(ctx, ctx, addr, "", tlsConf, conf, true)
 }
 
-func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsConf *tls.Config, conf *Config, use0RTT bool) (EarlyConnection, error) {
+// dial dials the address.
+// The tracingCtx is passed to the Config.Tracer.
+func (t *Transport) dial(ctx, tracingCtx context.Context, addr net.Addr, host string, tlsConf *tls.Config, conf *Config, use0RTT bool) (EarlyConnection, error) {
+	if t.EnableProxyProtocol {
+		return nil, errors.New("quic: can't dial when the PROXY protocol is enabled")
+	}
 	if err := validateConfig(conf); err != nil {
 		return nil, err
 	}
@@ -193,7 +236,7 @@ func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsCon
 	tlsConf = tlsConf.Clone()
 	tlsConf.MinVersion = tls.VersionTLS13
 	setTLSConfigServerName(tlsConf, addr, host)
-	return dial(ctx, newSendConn(t.conn, addr, packetInfo{}, utils.DefaultLogger), t.connIDGenerator, t.handlerMap, tlsConf, conf, onClose, use0RTT)
+	return dial(ctx, tracingCtx, newSendConn(t.conn, addr, packetInfo{}, conf.DSCP, utils.DefaultLogger), t.connIDGenerator, t.handlerMap, tlsConf, conf, onClose, use0RTT)
 }
 
 func (t *Transport) init(allowZeroLengthConnIDs bool) error {
@@ -211,6 +254,9 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 		}
 
 		t.logger = utils.DefaultLogger // TODO: make this configurable
+		if t.EnableProxyProtocol {
+			conn = newProxyProtocolConn(conn, t.logger)
+		}
 		t.conn = conn
 		t.handlerMap = newPacketHandlerMap(t.StatelessResetKey, t.enqueueClosePacket, t.logger)
 		t.listening = make(chan struct{})
@@ -250,7 +296,7 @@ func (t *Transport) WriteTo(b []byte, addr net.Addr) (int, error) {
 	if err := t.init(false); err != nil {
 		return 0, err
 	}
-	return t.conn.WritePacket(b, addr, nil, 0, protocol.ECNUnsupported)
+	return t.conn.WritePacket(b, addr, nil, 0, protocol.ECNUnsupported, 0)
 }
 
 func (t *Transport) enqueueClosePacket(p closePacket) {
@@ -268,7 +314,7 @@ func (t *Transport) runSendQueue() {
 		case <-t.listening:
 			return
 		case p := <-t.closeQueue:
-			t.conn.WritePacket(p.payload, p.addr, p.info.OOB(), 0, protocol.ECNUnsupported)
+			t.conn.WritePacket(p.payload, p.addr, p.info.OOB(), 0, protocol.ECNUnsupported, 0)
 		case p := <-t.statelessResetQueue:
 			t.sendStatelessReset(p)
 		}
@@ -436,7 +482,7 @@ func (t *Transport) sendStatelessReset(p receivedPacket) {
 	rand.Read(data)
 	data[0] = (data[0] & 0x7f) | 0x40
 	data = append(data, token[:]...)
-	if _, err := t.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported); err != nil {
+	if _, err := t.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported, 0); err != nil {
 		t.logger.Debugf("Error sending Stateless Reset to %s: %s", p.remoteAddr, err)
 	}
 }
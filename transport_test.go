@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/handshake"
 	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -123,6 +124,63 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("rotates the token generator key", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan)}
+		ln, err := tr.Listen(&tls.Config{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		tokenGen := ln.baseServer.tokenGenerator
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		token, err := tokenGen.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+
+		var key TokenGeneratorKey
+		rand.Read(key[:])
+		tr.RotateTokenGeneratorKey(key)
+		Expect(*tr.TokenGeneratorKey).To(Equal(key))
+		// tokens encrypted with the previous key are still accepted
+		_, err = tokenGen.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		// new tokens are encrypted using the new key
+		newToken, err := tokenGen.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = handshake.NewTokenGenerator(key).DecodeToken(newToken)
+		Expect(err).ToNot(HaveOccurred())
+
+		// shutdown
+		Expect(ln.Close()).To(Succeed())
+		close(packetChan)
+		tr.Close()
+	})
+
+	It("accepts tokens encrypted with the previous key after recreating the listener", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan)}
+		ln, err := tr.Listen(&tls.Config{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		oldToken, err := ln.baseServer.tokenGenerator.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		var key TokenGeneratorKey
+		rand.Read(key[:])
+		tr.RotateTokenGeneratorKey(key)
+		Expect(ln.Close()).To(Succeed())
+
+		ln, err = tr.Listen(&tls.Config{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = ln.baseServer.tokenGenerator.DecodeToken(oldToken)
+		Expect(err).ToNot(HaveOccurred())
+		newToken, err := ln.baseServer.tokenGenerator.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = handshake.NewTokenGenerator(key).DecodeToken(newToken)
+		Expect(err).ToNot(HaveOccurred())
+
+		// shutdown
+		Expect(ln.Close()).To(Succeed())
+		close(packetChan)
+		tr.Close()
+	})
+
 	It("drops unparseable QUIC packets", func() {
 		addr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
 		packetChan := make(chan packetToRead)
@@ -310,6 +368,12 @@ var _ = Describe("Transport", func() {
 		Expect(tr.Close()).To(Succeed())
 	})
 
+	It("refuses to dial when the PROXY protocol is enabled", func() {
+		tr := &Transport{Conn: newMockPacketConn(make(chan packetToRead)), EnableProxyProtocol: true}
+		_, err := tr.Dial(context.Background(), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 443}, &tls.Config{}, nil)
+		Expect(err).To(MatchError("quic: can't dial when the PROXY protocol is enabled"))
+	})
+
 	It("doesn't add the PacketConn to the multiplexer if (*Transport).init fails", func() {
 		packetChan := make(chan packetToRead)
 		pconn := newMockPacketConn(packetChan)